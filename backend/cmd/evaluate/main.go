@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"devswipe-backend/internal/config"
+	"devswipe-backend/internal/evaluation"
+	"devswipe-backend/internal/services"
+	"devswipe-backend/pkg/database"
+)
+
+func main() {
+	synthetic := flag.Bool("synthetic", false, "使用可复现的合成数据集，而不是数据库中的历史交互")
+	seed := flag.Uint64("seed", 42, "合成数据集的随机种子")
+	users := flag.Int("users", 200, "合成数据集的用户数")
+	projects := flag.Int("projects", 300, "合成数据集的项目数")
+	interactionsPerUser := flag.Int("interactions-per-user", 40, "合成数据集中每个用户的交互次数")
+	k := flag.Int("k", 10, "评估前K个推荐")
+	trainRatio := flag.Float64("train-ratio", 0.8, "按时间切分时训练集的比例")
+	configsPath := flag.String("configs", "", "推荐器配置文件（JSON数组，格式同实验变体）")
+	golden := flag.String("golden", "", "与该文件中的对比表比较，不一致时以非零状态退出")
+	updateGolden := flag.Bool("update-golden", false, "将结果写入 -golden 指定的文件")
+	flag.Parse()
+
+	// 加载配置
+	config.LoadConfig()
+
+	var ds *evaluation.Dataset
+	if *synthetic {
+		ds = evaluation.GenerateSynthetic(evaluation.SyntheticOptions{
+			Seed:                *seed,
+			Users:               *users,
+			Projects:            *projects,
+			InteractionsPerUser: *interactionsPerUser,
+		})
+	} else {
+		if err := database.InitDB(); err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+		defer database.CloseDB()
+
+		var err error
+		if ds, err = loadDataset(); err != nil {
+			log.Fatalf("Failed to load dataset: %v", err)
+		}
+	}
+
+	table, err := evaluate(ds, *configsPath, evaluation.Options{
+		K:          *k,
+		TrainRatio: *trainRatio,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load recommender configs: %v", err)
+	}
+	fmt.Print(table)
+
+	if *golden == "" {
+		return
+	}
+
+	if *updateGolden {
+		if err := os.WriteFile(*golden, []byte(table), 0644); err != nil {
+			log.Fatalf("Failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(*golden)
+	if err != nil {
+		log.Fatalf("Failed to read golden file: %v", err)
+	}
+	if strings.TrimSpace(string(expected)) != strings.TrimSpace(table) {
+		fmt.Fprintf(os.Stderr, "\nEvaluation results differ from %s, expected:\n%s", *golden, expected)
+		os.Exit(1)
+	}
+}
+
+// loadDataset 从数据库读取评估所需的全部数据
+func loadDataset() (*evaluation.Dataset, error) {
+	db := database.DB
+	ds := &evaluation.Dataset{}
+
+	if err := db.Find(&ds.Users).Error; err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+	if err := db.Preload("Tags").Find(&ds.Projects).Error; err != nil {
+		return nil, fmt.Errorf("failed to load projects: %w", err)
+	}
+	if err := db.Order("created_at ASC, id ASC").Find(&ds.Interactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load interactions: %w", err)
+	}
+	if err := db.Find(&ds.Follows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load follows: %w", err)
+	}
+
+	return ds, nil
+}
+
+// evaluate 在数据集上评估所有推荐器，返回 Markdown 对比表
+func evaluate(ds *evaluation.Dataset, configsPath string, opts evaluation.Options) (string, error) {
+	recommenders, err := buildRecommenders(configsPath)
+	if err != nil {
+		return "", err
+	}
+	results := evaluation.Evaluate(ds, recommenders, opts)
+	return evaluation.FormatTable(results, opts.K), nil
+}
+
+// evaluationParams 离线评估的基线排序参数。多样性参数固定为默认配置的取值，
+// 不读取 RECOMMENDATION_* 配置，评估结果不随部署环境变化；探索层是随机的，不启用
+func evaluationParams() services.RankingParams {
+	params := services.BaseRankingParams()
+	params.DiversityStrength = 0.3
+	params.DiversityWindow = 6
+	return params
+}
+
+// buildRecommenders 内置的基线推荐器加上配置文件中的排序变体
+func buildRecommenders(configsPath string) ([]evaluation.Recommender, error) {
+	defaults := evaluationParams()
+
+	recommenders := []evaluation.Recommender{
+		evaluation.LatestRecommender{},
+		evaluation.PopularRecommender{},
+		evaluation.RankingRecommender{Label: "ranking", Params: defaults},
+	}
+
+	if configsPath == "" {
+		return recommenders, nil
+	}
+
+	data, err := os.ReadFile(configsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var variants []config.VariantConfig
+	if err := json.Unmarshal(data, &variants); err != nil {
+		return nil, err
+	}
+
+	for _, variant := range variants {
+		switch variant.Strategy {
+		case "latest":
+			recommenders = append(recommenders, namedRecommender{variant.Name, evaluation.LatestRecommender{}})
+		case "popular":
+			recommenders = append(recommenders, namedRecommender{variant.Name, evaluation.PopularRecommender{}})
+		default:
+			recommenders = append(recommenders, evaluation.RankingRecommender{
+				Label:  variant.Name,
+				Params: defaults.WithOverrides(variant.Name, variant.Params),
+			})
+		}
+	}
+
+	return recommenders, nil
+}
+
+// namedRecommender 为内置推荐器指定配置中的名称
+type namedRecommender struct {
+	name string
+	evaluation.Recommender
+}
+
+func (r namedRecommender) Name() string { return r.name }
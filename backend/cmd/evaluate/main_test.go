@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"devswipe-backend/internal/config"
+	"devswipe-backend/internal/evaluation"
+)
+
+// TestSyntheticReportGolden 与 make evaluate 使用相同的合成数据集和配置，结果必须与对比表一致。
+// 有意修改排序算法后，用 make evaluate 加 -update-golden 重新生成对比表
+func TestSyntheticReportGolden(t *testing.T) {
+	// 环境变量中的推荐配置不应影响评估结果
+	t.Setenv("RECOMMENDATION_DIVERSITY_STRENGTH", "0.9")
+	t.Setenv("RECOMMENDATION_EXPLORATION_RATE", "0.5")
+	config.LoadConfig()
+
+	ds := evaluation.GenerateSynthetic(evaluation.SyntheticOptions{
+		Seed:                42,
+		Users:               200,
+		Projects:            300,
+		InteractionsPerUser: 40,
+	})
+
+	table, err := evaluate(ds, "testdata/configs.json", evaluation.Options{K: 10, TrainRatio: 0.8})
+	if err != nil {
+		t.Fatalf("evaluate() error = %v", err)
+	}
+
+	expected, err := os.ReadFile("testdata/synthetic_report.md")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if strings.TrimSpace(string(expected)) != strings.TrimSpace(table) {
+		t.Fatalf("evaluation results differ from testdata/synthetic_report.md\ngot:\n%s\nwant:\n%s", table, expected)
+	}
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 
 	"devswipe-backend/internal/config"
 	"devswipe-backend/internal/handlers"
 	"devswipe-backend/internal/middleware"
+	"devswipe-backend/internal/services"
 	"devswipe-backend/pkg/auth"
 	"devswipe-backend/pkg/cache"
 	"devswipe-backend/pkg/database"
@@ -18,6 +20,18 @@ func main() {
 	// 加载配置
 	config.LoadConfig()
 
+	// 设置Gin模式
+	if config.AppConfig.Server.Host == "localhost" {
+		gin.SetMode(gin.DebugMode)
+	} else {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// 生产环境禁止使用默认的JWT密钥
+	if gin.Mode() == gin.ReleaseMode && config.AppConfig.JWT.UsesDefaultSecret() {
+		log.Fatal("Refusing to start in release mode with the default JWT secret, set JWT_SECRET_KEY or JWT_ALGORITHM=RS256/EdDSA")
+	}
+
 	// 初始化JWT
 	auth.InitJWT()
 
@@ -32,18 +46,39 @@ func main() {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
+	// 同步角色：为配置的邮箱授予管理员角色
+	if err := services.NewAdminService().BootstrapRoles(config.AppConfig.Admin.Emails); err != nil {
+		log.Fatal("Failed to bootstrap roles:", err)
+	}
+
+	// 将引入标签词表之前的项目标签关联到规范标签
+	if linked, err := services.NewTagService().BackfillProjectTags(); err != nil {
+		log.Printf("Failed to backfill project tags: %v", err)
+	} else if linked > 0 {
+		log.Printf("Linked %d legacy project tags to canonical tags", linked)
+	}
+
 	// 初始化Redis
 	if err := cache.InitRedis(); err != nil {
 		log.Fatal("Failed to initialize Redis:", err)
 	}
 	defer cache.CloseRedis()
 
-	// 设置Gin模式
-	if config.AppConfig.Server.Host == "localhost" {
-		gin.SetMode(gin.DebugMode)
-	} else {
-		gin.SetMode(gin.ReleaseMode)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 加载非对称签名密钥，并定时轮换
+	signingKeyService := services.NewSigningKeyService()
+	if err := signingKeyService.Init(ctx); err != nil {
+		log.Fatal("Failed to initialize JWT signing keys:", err)
 	}
+	go signingKeyService.StartScheduler(ctx)
+
+	// 定时计算热榜
+	go services.NewTrendingService().StartScheduler(ctx)
+
+	// 定时删除注销冷静期已结束的账户
+	go services.NewAccountDeletionService().StartScheduler(ctx)
 
 	// 创建路由
 	router := gin.New()
@@ -61,47 +96,142 @@ func main() {
 		})
 	})
 
+	// JWT公钥，供其它服务校验 DevSwipe 签发的令牌
+	router.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, auth.JWKS())
+	})
+
 	// 初始化处理器
 	userHandler := handlers.NewUserHandler()
 	projectHandler := handlers.NewProjectHandler()
+	experimentHandler := handlers.NewExperimentHandler()
+	interactionHandler := handlers.NewInteractionHandler()
+	oauthHandler := handlers.NewOAuthHandler()
+	adminHandler := handlers.NewAdminHandler()
+	accessTokenHandler := handlers.NewAccessTokenHandler()
+	mfaHandler := handlers.NewMFAHandler()
+	sessionHandler := handlers.NewSessionHandler()
+	blockHandler := handlers.NewBlockHandler()
+	tagHandler := handlers.NewTagHandler()
 
 	// API路由组
 	api := router.Group("/api/v1")
 	{
 		// 认证路由
-		auth := api.Group("/auth")
+		authRoutes := api.Group("/auth")
 		{
-			auth.POST("/register", userHandler.Register)
-			auth.POST("/login", userHandler.Login)
+			authRoutes.POST("/register", middleware.RateLimitMiddleware("register", config.AppConfig.RateLimit.Register), userHandler.Register)
+			authRoutes.POST("/login", middleware.RateLimitMiddleware("login", config.AppConfig.RateLimit.Login), userHandler.Login)
+			authRoutes.POST("/login/mfa", middleware.RateLimitMiddleware("login_mfa", config.AppConfig.RateLimit.LoginMFA), userHandler.LoginMFA)
+			authRoutes.POST("/password/forgot", middleware.RateLimitMiddleware("password_forgot", config.AppConfig.RateLimit.PasswordForgot), userHandler.ForgotPassword)
+			authRoutes.POST("/password/reset", middleware.RateLimitMiddleware("password_reset", config.AppConfig.RateLimit.PasswordReset), userHandler.ResetPassword)
+			authRoutes.POST("/verify-email", middleware.RateLimitMiddleware("verify_email", config.AppConfig.RateLimit.VerifyEmail), userHandler.VerifyEmail)
+			authRoutes.GET("/oauth/:provider/start", oauthHandler.Start)
+			authRoutes.POST("/oauth/:provider/callback", middleware.RateLimitMiddleware("oauth_callback", config.AppConfig.RateLimit.OAuthCallback), oauthHandler.Callback)
+			authRoutes.POST("/unlock", middleware.RateLimitMiddleware("unlock", config.AppConfig.RateLimit.Unlock), userHandler.UnlockAccount)
 		}
 
 		// 用户路由
 		users := api.Group("/users")
 		{
-			users.GET("/me", middleware.AuthMiddleware(), userHandler.GetProfile)
-			users.PUT("/me", middleware.AuthMiddleware(), userHandler.UpdateProfile)
-			users.GET("/:id/followers", userHandler.GetFollowers)
-			users.GET("/:id/following", userHandler.GetFollowing)
-			users.POST("/:id/follow", middleware.AuthMiddleware(), userHandler.FollowUser)
-			users.DELETE("/:id/follow", middleware.AuthMiddleware(), userHandler.UnfollowUser)
-			users.GET("/:id/projects", projectHandler.GetUserProjects)
+			users.GET("/me", middleware.AuthMiddleware(auth.ScopeProfileRead), userHandler.GetProfile)
+			users.PUT("/me", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), userHandler.UpdateProfile)
+			users.DELETE("/me", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), userHandler.DeleteAccount)
+			users.POST("/me/deletion/cancel", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), userHandler.CancelDeletion)
+			users.GET("/me/export", middleware.AuthMiddleware(), userHandler.ExportData)
+			users.POST("/me/verify-email", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), userHandler.ResendVerificationEmail)
+			users.GET("/me/identities", middleware.AuthMiddleware(), oauthHandler.ListIdentities)
+			users.POST("/me/identities/:provider", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), oauthHandler.StartLink)
+			users.DELETE("/me/identities/:provider", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), oauthHandler.Unlink)
+			users.GET("/me/sessions", middleware.AuthMiddleware(), sessionHandler.ListSessions)
+			users.DELETE("/me/sessions", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), sessionHandler.RevokeAllSessions)
+			users.DELETE("/me/sessions/:id", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), sessionHandler.RevokeSession)
+			users.GET("/me/mfa", middleware.AuthMiddleware(), mfaHandler.GetStatus)
+			users.POST("/me/mfa/enroll", middleware.AuthMiddleware(), mfaHandler.Enroll)
+			users.POST("/me/mfa/confirm", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), mfaHandler.Confirm)
+			users.POST("/me/mfa/disable", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), mfaHandler.Disable)
+			users.POST("/me/mfa/recovery-codes", middleware.AuthMiddleware(), mfaHandler.RegenerateRecoveryCodes)
+			users.GET("/me/tokens", middleware.AuthMiddleware(), accessTokenHandler.ListTokens)
+			users.POST("/me/tokens", middleware.AuthMiddleware(), accessTokenHandler.CreateToken)
+			users.DELETE("/me/tokens/:id", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), accessTokenHandler.RevokeToken)
+			users.GET("/me/interactions", middleware.AuthMiddleware(auth.ScopeInteractionsRead), interactionHandler.GetMyInteractions)
+			users.GET("/me/preferences", middleware.AuthMiddleware(), userHandler.GetPreferences)
+			users.PUT("/me/preferences", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), userHandler.UpdatePreferences)
+			users.PUT("/me/privacy", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), userHandler.UpdatePrivacy)
+			users.GET("/me/blocks", middleware.AuthMiddleware(), blockHandler.ListBlocked)
+			users.GET("/me/mutes", middleware.AuthMiddleware(), blockHandler.ListMuted)
+			users.GET("/search", middleware.OptionalAuthMiddleware(), userHandler.SearchUsers)
+			users.GET("/by-username/:username", middleware.OptionalAuthMiddleware(), userHandler.GetUserByUsername)
+			users.GET("/:id", middleware.OptionalAuthMiddleware(), userHandler.GetUser)
+			users.GET("/:id/followers", middleware.OptionalAuthMiddleware(), userHandler.GetFollowers)
+			users.GET("/:id/following", middleware.OptionalAuthMiddleware(), userHandler.GetFollowing)
+			users.POST("/:id/follow", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), userHandler.FollowUser)
+			users.DELETE("/:id/follow", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), userHandler.UnfollowUser)
+			users.POST("/:id/block", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), blockHandler.BlockUser)
+			users.DELETE("/:id/block", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), blockHandler.UnblockUser)
+			users.POST("/:id/mute", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), blockHandler.MuteUser)
+			users.DELETE("/:id/mute", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), blockHandler.UnmuteUser)
+			users.GET("/:id/projects", middleware.OptionalAuthMiddleware(), projectHandler.GetUserProjects)
 		}
 
 		// 项目路由
 		projects := api.Group("/projects")
 		{
 			projects.GET("/feed", middleware.OptionalAuthMiddleware(), projectHandler.GetFeed)
-			projects.GET("/search", projectHandler.SearchProjects)
+			projects.GET("/feed/following", middleware.AuthMiddleware(), projectHandler.GetFollowingFeed)
+			projects.GET("/search", middleware.OptionalAuthMiddleware(), projectHandler.SearchProjects)
+			projects.GET("/trending", middleware.OptionalAuthMiddleware(), projectHandler.GetTrending)
 			// 同时支持带斜杠和不带斜杠的创建接口，避免前端或工具差异导致404
-			projects.POST("/", middleware.AuthMiddleware(), projectHandler.CreateProject)
-			projects.POST("", middleware.AuthMiddleware(), projectHandler.CreateProject)
-			projects.GET("/:id", projectHandler.GetProject)
-			projects.PUT("/:id", middleware.AuthMiddleware(), projectHandler.UpdateProject)
-			projects.DELETE("/:id", middleware.AuthMiddleware(), projectHandler.DeleteProject)
+			projects.POST("/", middleware.AuthMiddleware(auth.ScopeProjectsWrite), middleware.IdempotencyMiddleware(), projectHandler.CreateProject)
+			projects.POST("", middleware.AuthMiddleware(auth.ScopeProjectsWrite), middleware.IdempotencyMiddleware(), projectHandler.CreateProject)
+			projects.GET("/:id", middleware.OptionalAuthMiddleware(), projectHandler.GetProject)
+			projects.PUT("/:id", middleware.AuthMiddleware(auth.ScopeProjectsWrite), middleware.IdempotencyMiddleware(), projectHandler.UpdateProject)
+			projects.DELETE("/:id", middleware.AuthMiddleware(auth.ScopeProjectsWrite), middleware.IdempotencyMiddleware(), projectHandler.DeleteProject)
 			projects.GET("/:id/stats", projectHandler.GetProjectStats)
-			projects.POST("/:id/interact", middleware.AuthMiddleware(), projectHandler.InteractWithProject)
-			projects.POST("/:id/comments", middleware.AuthMiddleware(), projectHandler.AddComment)
-			projects.GET("/:id/comments", projectHandler.GetComments)
+			projects.POST("/:id/interact", middleware.AuthMiddleware(auth.ScopeInteractionsWrite), middleware.IdempotencyMiddleware(), middleware.RateLimitMiddleware("interact", config.AppConfig.RateLimit.Interact), projectHandler.InteractWithProject)
+			projects.POST("/:id/comments", middleware.AuthMiddleware(auth.ScopeInteractionsWrite), middleware.IdempotencyMiddleware(), middleware.RateLimitMiddleware("comment", config.AppConfig.RateLimit.Comment), projectHandler.AddComment)
+			projects.GET("/:id/comments", middleware.OptionalAuthMiddleware(), projectHandler.GetComments)
+		}
+
+		// 标签路由
+		tags := api.Group("/tags")
+		{
+			tags.GET("", tagHandler.SuggestTags)
+			tags.GET("/popular", tagHandler.GetPopularTags)
+			tags.GET("/:name", middleware.OptionalAuthMiddleware(), tagHandler.GetTag)
+		}
+
+		// 交互路由
+		interactions := api.Group("/interactions")
+		{
+			interactions.POST("/batch", middleware.AuthMiddleware(auth.ScopeInteractionsWrite), middleware.IdempotencyMiddleware(), middleware.WeightedRateLimitMiddleware("interact", config.AppConfig.RateLimit.Interact, handlers.BatchInteractionCost), interactionHandler.BatchInteract)
+		}
+
+		// 实验路由
+		experiments := api.Group("/experiments")
+		{
+			experiments.GET("/:name/report", middleware.AuthMiddleware(), middleware.RequirePermission(auth.PermReadExperiments), experimentHandler.GetReport)
+		}
+
+		// 管理路由，仅版主及以上角色可访问
+		admin := api.Group("/admin", middleware.AuthMiddleware(), middleware.RequireRole(auth.RoleModerator), middleware.IdempotencyMiddleware())
+		{
+			admin.GET("/users", middleware.RequirePermission(auth.PermManageUsers), adminHandler.ListUsers)
+			admin.GET("/users/:id", middleware.RequirePermission(auth.PermManageUsers), adminHandler.GetUser)
+			admin.PUT("/users/:id/role", middleware.RequirePermission(auth.PermManageRoles), adminHandler.UpdateRole)
+			admin.POST("/users/:id/unlock", middleware.RequirePermission(auth.PermManageUsers), adminHandler.UnlockUser)
+			admin.DELETE("/users/:id/mfa", middleware.RequirePermission(auth.PermManageUsers), adminHandler.ResetMFA)
+			admin.GET("/users/:id/audit-logs", middleware.RequirePermission(auth.PermReadAuditLogs), adminHandler.GetAuditLogs)
+			admin.GET("/projects", middleware.RequirePermission(auth.PermModerateProjects), adminHandler.ListProjects)
+			admin.PUT("/projects/:id/visibility", middleware.RequirePermission(auth.PermModerateProjects), adminHandler.SetProjectVisibility)
+			admin.DELETE("/projects/:id", middleware.RequirePermission(auth.PermModerateProjects), adminHandler.DeleteProject)
+			admin.DELETE("/comments/:id", middleware.RequirePermission(auth.PermModerateComments), adminHandler.DeleteComment)
+			admin.GET("/tags", middleware.RequirePermission(auth.PermManageTags), tagHandler.ListTags)
+			admin.PUT("/tags/:id", middleware.RequirePermission(auth.PermManageTags), tagHandler.UpdateTag)
+			admin.POST("/tags/:id/synonyms", middleware.RequirePermission(auth.PermManageTags), tagHandler.AddSynonym)
+			admin.DELETE("/tags/:id/synonyms/:synonym", middleware.RequirePermission(auth.PermManageTags), tagHandler.RemoveSynonym)
+			admin.POST("/tags/:id/merge", middleware.RequirePermission(auth.PermManageTags), tagHandler.MergeTags)
 		}
 	}
 
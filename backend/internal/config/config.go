@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"log"
 	"strings"
 
@@ -9,10 +10,20 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
+	Server         ServerConfig
+	Database       DatabaseConfig
+	Redis          RedisConfig
+	JWT            JWTConfig
+	Recommendation RecommendationConfig
+	Trending       TrendingConfig
+	RateLimit      RateLimitConfig
+	LoginGuard     LoginGuardConfig
+	Mail           MailConfig
+	Account        AccountConfig
+	OAuth          OAuthConfig
+	Admin          AdminConfig
+	MFA            MFAConfig
+	Experiments    []ExperimentConfig
 }
 
 type ServerConfig struct {
@@ -35,9 +46,120 @@ type RedisConfig struct {
 	DB       int
 }
 
+// DefaultJWTSecret 开发环境的默认密钥，release 模式下禁止使用
+const DefaultJWTSecret = "your-secret-key"
+
 type JWTConfig struct {
-	SecretKey string
-	ExpiresIn int // hours
+	SecretKey        string
+	ExpiresIn        int    // hours
+	Algorithm        string // HS256 使用 SecretKey 签名；RS256、EdDSA 使用数据库中的密钥对并定期轮换
+	RotationInterval int    // 非对称密钥的轮换周期（天）
+}
+
+// UsesDefaultSecret 是否仍在使用默认的 HS256 密钥签名
+func (c JWTConfig) UsesDefaultSecret() bool {
+	return c.Algorithm == "HS256" && c.SecretKey == DefaultJWTSecret
+}
+
+type RecommendationConfig struct {
+	ExplorationRate           float64 // 预留给探索项目的推荐位比例
+	ExplorationMaxImpressions int     // 曝光数低于该值的项目视为曝光不足
+	DiversityStrength         float64 // 多样性重排强度，0表示关闭
+	DiversityWindow           int     // 多样性重排回看的窗口大小（通常为一页）
+}
+
+type TrendingConfig struct {
+	Gravity         float64 // 时间衰减指数，越大旧项目掉得越快
+	RefreshInterval int     // 重新计算间隔（分钟）
+}
+
+// RateLimitConfig 各路由的限流规则，每个路由单独计数
+type RateLimitConfig struct {
+	Enabled        bool
+	Login          RateLimitRule
+	LoginMFA       RateLimitRule
+	Register       RateLimitRule
+	PasswordForgot RateLimitRule
+	PasswordReset  RateLimitRule
+	VerifyEmail    RateLimitRule
+	OAuthCallback  RateLimitRule
+	Unlock         RateLimitRule
+	Comment        RateLimitRule
+	Interact       RateLimitRule
+}
+
+// RateLimitRule 单个路由的滑动窗口限流规则，上限为0表示不按该维度限流
+type RateLimitRule struct {
+	Window  int // 窗口长度（秒）
+	PerIP   int // 每个IP在窗口内的请求上限
+	PerUser int // 每个登录用户在窗口内的请求上限
+}
+
+// LoginGuardConfig 登录防暴力破解配置
+type LoginGuardConfig struct {
+	FreeAttempts       int // 不做退避的失败次数
+	BaseDelay          int // 退避的初始等待时间（秒），之后每次失败翻倍
+	MaxDelay           int // 退避等待时间上限（秒）
+	LockoutThreshold   int // 账户连续失败达到该次数后临时锁定
+	LockoutDuration    int // 账户锁定时长（分钟）
+	IPLockoutThreshold int // 同一IP失败达到该次数后临时封禁
+	FailureWindow      int // 失败次数的统计窗口（分钟）
+}
+
+type MailConfig struct {
+	Driver       string // smtp、file 或 log
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	FileDir      string // file 驱动保存邮件的目录
+}
+
+type AccountConfig struct {
+	FrontendURL                   string // 邮件中链接指向的前端地址
+	PasswordResetTTL              int    // 密码重置令牌有效期（分钟）
+	EmailVerificationTTL          int    // 邮箱验证令牌有效期（小时）
+	RequireVerifiedEmailToPublish bool   // 未验证邮箱的用户是否禁止发布项目
+	DeletionGracePeriod           int    // 申请注销后到数据被删除前的冷静期（天），期间可以撤销
+}
+
+type OAuthConfig struct {
+	RedirectURL string // 前端的回调地址，第三方授权后带着 code 和 state 跳回这里
+	GitHub      OAuthProviderConfig
+}
+
+type OAuthProviderConfig struct {
+	ClientID     string // 为空时不启用该提供方
+	ClientSecret string
+	AuthURL      string
+	TokenURL     string
+	APIURL       string
+	Scopes       []string
+}
+
+type AdminConfig struct {
+	Emails []string // 启动时授予管理员角色的邮箱
+}
+
+type MFAConfig struct {
+	Issuer       string // 认证器App中显示的发行方名称
+	ChallengeTTL int    // 登录第二步的MFA令牌有效期（分钟）
+}
+
+// ExperimentConfig A/B实验定义，同一时间只有第一个启用的实验生效
+type ExperimentConfig struct {
+	Name     string          `json:"name"`
+	Enabled  bool            `json:"enabled"`
+	BucketBy string          `json:"bucket_by"` // user 或 session
+	Variants []VariantConfig `json:"variants"`
+}
+
+type VariantConfig struct {
+	Name     string             `json:"name"`
+	Weight   int                `json:"weight"`   // 流量权重
+	Strategy string             `json:"strategy"` // recommended（默认）或 latest
+	Params   map[string]float64 `json:"params"`   // 覆盖推荐参数，如 tag_weight、diversity_strength
 }
 
 var AppConfig *Config
@@ -59,8 +181,57 @@ func LoadConfig() {
 	viper.SetDefault("redis.port", "6379")
 	viper.SetDefault("redis.password", "")
 	viper.SetDefault("redis.db", 0)
-	viper.SetDefault("jwt.secret_key", "your-secret-key")
+	viper.SetDefault("jwt.secret_key", DefaultJWTSecret)
 	viper.SetDefault("jwt.expires_in", 24)
+	viper.SetDefault("jwt.algorithm", "HS256")
+	viper.SetDefault("jwt.rotation_interval", 30)
+	viper.SetDefault("trending.gravity", 1.8)
+	viper.SetDefault("trending.refresh_interval", 10)
+	viper.SetDefault("rate_limit.enabled", true)
+	setRateLimitDefaults("login", 60, 10, 0)
+	setRateLimitDefaults("login_mfa", 60, 10, 0)
+	setRateLimitDefaults("register", 3600, 5, 0)
+	setRateLimitDefaults("password_forgot", 3600, 5, 0)
+	setRateLimitDefaults("password_reset", 3600, 10, 0)
+	setRateLimitDefaults("verify_email", 3600, 20, 0)
+	setRateLimitDefaults("oauth_callback", 60, 10, 0)
+	setRateLimitDefaults("unlock", 3600, 10, 0)
+	setRateLimitDefaults("comment", 60, 60, 20)
+	setRateLimitDefaults("interact", 60, 300, 120)
+	viper.SetDefault("login_guard.free_attempts", 3)
+	viper.SetDefault("login_guard.base_delay", 1)
+	viper.SetDefault("login_guard.max_delay", 300)
+	viper.SetDefault("login_guard.lockout_threshold", 10)
+	viper.SetDefault("login_guard.lockout_duration", 15)
+	viper.SetDefault("login_guard.ip_lockout_threshold", 50)
+	viper.SetDefault("login_guard.failure_window", 60)
+	viper.SetDefault("mail.driver", "log")
+	viper.SetDefault("mail.from", "DevSwipe <no-reply@devswipe.local>")
+	viper.SetDefault("mail.smtp_host", "localhost")
+	viper.SetDefault("mail.smtp_port", "587")
+	viper.SetDefault("mail.smtp_username", "")
+	viper.SetDefault("mail.smtp_password", "")
+	viper.SetDefault("mail.file_dir", "tmp/mail")
+	viper.SetDefault("account.frontend_url", "http://localhost:3000")
+	viper.SetDefault("account.password_reset_ttl", 60)
+	viper.SetDefault("account.email_verification_ttl", 48)
+	viper.SetDefault("account.require_verified_email_to_publish", false)
+	viper.SetDefault("account.deletion_grace_period", 14)
+	viper.SetDefault("oauth.redirect_url", "http://localhost:3000/oauth/callback")
+	viper.SetDefault("oauth.github.client_id", "")
+	viper.SetDefault("oauth.github.client_secret", "")
+	viper.SetDefault("oauth.github.auth_url", "https://github.com/login/oauth/authorize")
+	viper.SetDefault("oauth.github.token_url", "https://github.com/login/oauth/access_token")
+	viper.SetDefault("oauth.github.api_url", "https://api.github.com")
+	viper.SetDefault("oauth.github.scopes", "read:user,user:email")
+	viper.SetDefault("admin.emails", "")
+	viper.SetDefault("mfa.issuer", "DevSwipe")
+	viper.SetDefault("mfa.challenge_ttl", 5)
+	viper.SetDefault("experiments", "")
+	viper.SetDefault("recommendation.exploration_rate", 0.2)
+	viper.SetDefault("recommendation.exploration_max_impressions", 50)
+	viper.SetDefault("recommendation.diversity_strength", 0.3)
+	viper.SetDefault("recommendation.diversity_window", 6)
 
 	// Override with environment variables
 	viper.AutomaticEnv()
@@ -85,8 +256,117 @@ func LoadConfig() {
 			DB:       viper.GetInt("redis.db"),
 		},
 		JWT: JWTConfig{
-			SecretKey: viper.GetString("jwt.secret_key"),
-			ExpiresIn: viper.GetInt("jwt.expires_in"),
+			SecretKey:        viper.GetString("jwt.secret_key"),
+			ExpiresIn:        viper.GetInt("jwt.expires_in"),
+			Algorithm:        viper.GetString("jwt.algorithm"),
+			RotationInterval: viper.GetInt("jwt.rotation_interval"),
+		},
+		Recommendation: RecommendationConfig{
+			ExplorationRate:           viper.GetFloat64("recommendation.exploration_rate"),
+			ExplorationMaxImpressions: viper.GetInt("recommendation.exploration_max_impressions"),
+			DiversityStrength:         viper.GetFloat64("recommendation.diversity_strength"),
+			DiversityWindow:           viper.GetInt("recommendation.diversity_window"),
+		},
+		Trending: TrendingConfig{
+			Gravity:         viper.GetFloat64("trending.gravity"),
+			RefreshInterval: viper.GetInt("trending.refresh_interval"),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:        viper.GetBool("rate_limit.enabled"),
+			Login:          loadRateLimitRule("login"),
+			LoginMFA:       loadRateLimitRule("login_mfa"),
+			Register:       loadRateLimitRule("register"),
+			PasswordForgot: loadRateLimitRule("password_forgot"),
+			PasswordReset:  loadRateLimitRule("password_reset"),
+			VerifyEmail:    loadRateLimitRule("verify_email"),
+			OAuthCallback:  loadRateLimitRule("oauth_callback"),
+			Unlock:         loadRateLimitRule("unlock"),
+			Comment:        loadRateLimitRule("comment"),
+			Interact:       loadRateLimitRule("interact"),
+		},
+		LoginGuard: LoginGuardConfig{
+			FreeAttempts:       viper.GetInt("login_guard.free_attempts"),
+			BaseDelay:          viper.GetInt("login_guard.base_delay"),
+			MaxDelay:           viper.GetInt("login_guard.max_delay"),
+			LockoutThreshold:   viper.GetInt("login_guard.lockout_threshold"),
+			LockoutDuration:    viper.GetInt("login_guard.lockout_duration"),
+			IPLockoutThreshold: viper.GetInt("login_guard.ip_lockout_threshold"),
+			FailureWindow:      viper.GetInt("login_guard.failure_window"),
 		},
+		Mail: MailConfig{
+			Driver:       viper.GetString("mail.driver"),
+			From:         viper.GetString("mail.from"),
+			SMTPHost:     viper.GetString("mail.smtp_host"),
+			SMTPPort:     viper.GetString("mail.smtp_port"),
+			SMTPUsername: viper.GetString("mail.smtp_username"),
+			SMTPPassword: viper.GetString("mail.smtp_password"),
+			FileDir:      viper.GetString("mail.file_dir"),
+		},
+		Account: AccountConfig{
+			FrontendURL:                   viper.GetString("account.frontend_url"),
+			PasswordResetTTL:              viper.GetInt("account.password_reset_ttl"),
+			EmailVerificationTTL:          viper.GetInt("account.email_verification_ttl"),
+			RequireVerifiedEmailToPublish: viper.GetBool("account.require_verified_email_to_publish"),
+			DeletionGracePeriod:           viper.GetInt("account.deletion_grace_period"),
+		},
+		OAuth: OAuthConfig{
+			RedirectURL: viper.GetString("oauth.redirect_url"),
+			GitHub: OAuthProviderConfig{
+				ClientID:     viper.GetString("oauth.github.client_id"),
+				ClientSecret: viper.GetString("oauth.github.client_secret"),
+				AuthURL:      viper.GetString("oauth.github.auth_url"),
+				TokenURL:     viper.GetString("oauth.github.token_url"),
+				APIURL:       viper.GetString("oauth.github.api_url"),
+				Scopes:       splitConfigList(viper.GetString("oauth.github.scopes")),
+			},
+		},
+		Admin: AdminConfig{
+			Emails: splitConfigList(viper.GetString("admin.emails")),
+		},
+		MFA: MFAConfig{
+			Issuer:       viper.GetString("mfa.issuer"),
+			ChallengeTTL: viper.GetInt("mfa.challenge_ttl"),
+		},
+		Experiments: loadExperiments(viper.GetString("experiments")),
+	}
+}
+
+// setRateLimitDefaults 设置路由限流规则的默认值，环境变量如 RATE_LIMIT_LOGIN_PER_IP
+func setRateLimitDefaults(route string, window, perIP, perUser int) {
+	viper.SetDefault("rate_limit."+route+".window", window)
+	viper.SetDefault("rate_limit."+route+".per_ip", perIP)
+	viper.SetDefault("rate_limit."+route+".per_user", perUser)
+}
+
+func loadRateLimitRule(route string) RateLimitRule {
+	return RateLimitRule{
+		Window:  viper.GetInt("rate_limit." + route + ".window"),
+		PerIP:   viper.GetInt("rate_limit." + route + ".per_ip"),
+		PerUser: viper.GetInt("rate_limit." + route + ".per_user"),
+	}
+}
+
+// splitConfigList 解析逗号分隔的配置项，忽略空白项
+func splitConfigList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadExperiments 解析JSON格式的实验定义
+func loadExperiments(raw string) []ExperimentConfig {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+
+	var experiments []ExperimentConfig
+	if err := json.Unmarshal([]byte(raw), &experiments); err != nil {
+		log.Printf("Invalid experiments config, experiments disabled: %v", err)
+		return nil
 	}
+	return experiments
 }
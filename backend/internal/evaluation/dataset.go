@@ -0,0 +1,43 @@
+package evaluation
+
+import (
+	"sort"
+	"time"
+
+	"devswipe-backend/internal/models"
+)
+
+// Dataset 离线评估使用的数据快照
+type Dataset struct {
+	Users        []models.User
+	Projects     []models.Project // 需要预加载Tags
+	Interactions []models.UserInteraction
+	Follows      []models.UserFollow
+}
+
+// SortInteractions 按时间顺序排列交互记录，时间相同时按ID排序
+func (d *Dataset) SortInteractions() {
+	sort.SliceStable(d.Interactions, func(i, j int) bool {
+		a, b := d.Interactions[i], d.Interactions[j]
+		if a.CreatedAt.Equal(b.CreatedAt) {
+			return a.ID < b.ID
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+}
+
+// Cutoff 返回按时间切分训练集/测试集的时间点，trainRatio 为训练集占交互总数的比例
+func (d *Dataset) Cutoff(trainRatio float64) time.Time {
+	if len(d.Interactions) == 0 {
+		return time.Now()
+	}
+
+	index := int(float64(len(d.Interactions)) * trainRatio)
+	index = max(0, min(index, len(d.Interactions)-1))
+	return d.Interactions[index].CreatedAt
+}
+
+// isPositive 判断一次交互是否为正反馈
+func isPositive(interactionType string) bool {
+	return interactionType == "like" || interactionType == "super_like"
+}
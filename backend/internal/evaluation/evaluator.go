@@ -0,0 +1,177 @@
+package evaluation
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"devswipe-backend/internal/models"
+)
+
+// Options 离线评估参数
+type Options struct {
+	K          int     // 评估前K个推荐
+	TrainRatio float64 // 训练集占交互总数的比例
+}
+
+// Result 单个推荐器的评估结果，除Coverage外均为测试用户的平均值
+type Result struct {
+	Name      string
+	Users     int
+	Precision float64
+	Recall    float64
+	NDCG      float64
+	Coverage  float64 // 被推荐过的项目占候选项目库的比例
+	Novelty   float64 // 推荐项目的平均自信息 -log2(流行度)
+}
+
+// Evaluate 按时间切分数据集，回放训练集后对每个推荐器计算各项指标
+func Evaluate(ds *Dataset, recommenders []Recommender, opts Options) []Result {
+	ds.SortInteractions()
+	cutoff := ds.Cutoff(opts.TrainRatio)
+	state := replayTraining(ds, cutoff)
+
+	// 测试集中的正反馈，只保留切分时已存在的项目
+	relevant := make(map[int64]map[int64]bool)
+	for _, interaction := range ds.Interactions {
+		if interaction.CreatedAt.Before(cutoff) || !isPositive(interaction.InteractionType) {
+			continue
+		}
+		if _, ok := state.Projects[interaction.ProjectID]; !ok {
+			continue
+		}
+		if state.Interacted[interaction.UserID][interaction.ProjectID] {
+			continue
+		}
+		if relevant[interaction.UserID] == nil {
+			relevant[interaction.UserID] = make(map[int64]bool)
+		}
+		relevant[interaction.UserID][interaction.ProjectID] = true
+	}
+
+	testUsers := make([]int64, 0, len(relevant))
+	for userID := range relevant {
+		testUsers = append(testUsers, userID)
+	}
+	sort.Slice(testUsers, func(i, j int) bool { return testUsers[i] < testUsers[j] })
+
+	catalog := make([]int64, 0, len(state.Projects))
+	for id, project := range state.Projects {
+		if project.IsPublic {
+			catalog = append(catalog, id)
+		}
+	}
+	sort.Slice(catalog, func(i, j int) bool { return catalog[i] < catalog[j] })
+
+	results := make([]Result, 0, len(recommenders))
+	for _, recommender := range recommenders {
+		result := Result{Name: recommender.Name(), Users: len(testUsers)}
+		recommended := make(map[int64]bool)
+		noveltyUsers := 0
+
+		for _, userID := range testUsers {
+			candidates := make([]models.Project, 0, len(catalog))
+			for _, id := range catalog {
+				if !state.Interacted[userID][id] {
+					candidates = append(candidates, state.Projects[id])
+				}
+			}
+
+			ids := recommender.Recommend(state, userID, candidates, opts.K)
+			for _, id := range ids {
+				recommended[id] = true
+			}
+
+			result.Precision += precisionAtK(ids, relevant[userID], opts.K)
+			result.Recall += recallAtK(ids, relevant[userID])
+			result.NDCG += ndcgAtK(ids, relevant[userID], opts.K)
+			if len(ids) > 0 {
+				result.Novelty += novelty(ids, state.Popularity, state.TrainedUsers)
+				noveltyUsers++
+			}
+		}
+
+		if n := float64(len(testUsers)); n > 0 {
+			result.Precision /= n
+			result.Recall /= n
+			result.NDCG /= n
+		}
+		if noveltyUsers > 0 {
+			result.Novelty /= float64(noveltyUsers)
+		}
+		if len(catalog) > 0 {
+			result.Coverage = float64(len(recommended)) / float64(len(catalog))
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func precisionAtK(ids []int64, relevant map[int64]bool, k int) float64 {
+	if k == 0 {
+		return 0
+	}
+	return float64(hits(ids, relevant)) / float64(k)
+}
+
+func recallAtK(ids []int64, relevant map[int64]bool) float64 {
+	if len(relevant) == 0 {
+		return 0
+	}
+	return float64(hits(ids, relevant)) / float64(len(relevant))
+}
+
+// ndcgAtK 二值相关性的NDCG
+func ndcgAtK(ids []int64, relevant map[int64]bool, k int) float64 {
+	dcg := 0.0
+	for i, id := range ids {
+		if relevant[id] {
+			dcg += 1 / math.Log2(float64(i+2))
+		}
+	}
+
+	idcg := 0.0
+	for i := 0; i < min(k, len(relevant)); i++ {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+
+	if idcg == 0 {
+		return 0
+	}
+	return dcg / idcg
+}
+
+// novelty 推荐列表的平均自信息，流行度做加一平滑
+func novelty(ids []int64, popularity map[int64]int, users int) float64 {
+	total := 0.0
+	for _, id := range ids {
+		p := float64(popularity[id]+1) / float64(users+1)
+		total += -math.Log2(p)
+	}
+	return total / float64(len(ids))
+}
+
+func hits(ids []int64, relevant map[int64]bool) int {
+	count := 0
+	for _, id := range ids {
+		if relevant[id] {
+			count++
+		}
+	}
+	return count
+}
+
+// FormatTable 输出各推荐器的对比表（Markdown格式）
+func FormatTable(results []Result, k int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "| recommender | users | precision@%d | recall@%d | ndcg@%d | coverage | novelty |\n", k, k, k)
+	b.WriteString("|---|---:|---:|---:|---:|---:|---:|\n")
+	for _, r := range results {
+		fmt.Fprintf(&b, "| %s | %d | %.4f | %.4f | %.4f | %.4f | %.4f |\n",
+			r.Name, r.Users, r.Precision, r.Recall, r.NDCG, r.Coverage, r.Novelty)
+	}
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"devswipe-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccessTokenHandler struct {
+	accessTokenService *services.AccessTokenService
+}
+
+func NewAccessTokenHandler() *AccessTokenHandler {
+	return &AccessTokenHandler{
+		accessTokenService: services.NewAccessTokenService(),
+	}
+}
+
+// CreateToken 创建个人访问令牌，明文令牌只在响应中返回一次
+func (h *AccessTokenHandler) CreateToken(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var req services.CreateAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	created, err := h.accessTokenService.Create(userID.(int64), &req, c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidScope), errors.Is(err, services.ErrTooManyAccessTokens):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to create access token",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// ListTokens 获取当前用户的个人访问令牌
+func (h *AccessTokenHandler) ListTokens(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	tokens, err := h.accessTokenService.List(userID.(int64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get access tokens",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tokens": tokens,
+	})
+}
+
+// RevokeToken 撤销个人访问令牌
+func (h *AccessTokenHandler) RevokeToken(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	tokenID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid token ID",
+		})
+		return
+	}
+
+	if err := h.accessTokenService.Revoke(userID.(int64), tokenID, c.ClientIP()); err != nil {
+		if errors.Is(err, services.ErrAccessTokenNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke access token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Access token revoked successfully",
+	})
+}
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"devswipe-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	adminService *services.AdminService
+}
+
+func NewAdminHandler() *AdminHandler {
+	return &AdminHandler{
+		adminService: services.NewAdminService(),
+	}
+}
+
+// ListUsers 按关键字和角色查询用户
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	limit, offset := adminPagination(c)
+
+	users, total, err := h.adminService.ListUsers(c.Query("q"), c.Query("role"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get users",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": users,
+		"total": total,
+	})
+}
+
+// GetUser 获取用户详情
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := adminParamID(c, "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.GetUser(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateRole 修改用户角色
+func (h *AdminHandler) UpdateRole(c *gin.Context) {
+	userID, ok := adminParamID(c, "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	var req services.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	err := h.adminService.UpdateRole(c.GetInt64("user_id"), userID, req.Role, c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAdminUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, services.ErrInvalidRole), errors.Is(err, services.ErrChangeOwnRole):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update role",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+	})
+}
+
+// UnlockUser 解除用户的登录锁定
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	userID, ok := adminParamID(c, "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.UnlockUser(c.GetInt64("user_id"), userID, c.ClientIP()); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account unlocked successfully",
+	})
+}
+
+// ResetMFA 关闭用户的两步验证
+func (h *AdminHandler) ResetMFA(c *gin.Context) {
+	userID, ok := adminParamID(c, "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.ResetMFA(c.GetInt64("user_id"), userID, c.ClientIP()); err != nil {
+		if errors.Is(err, services.ErrAdminUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to reset two-factor authentication",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Two-factor authentication reset successfully",
+	})
+}
+
+// GetAuditLogs 获取用户的审计日志
+func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
+	userID, ok := adminParamID(c, "Invalid user ID")
+	if !ok {
+		return
+	}
+	limit, offset := adminPagination(c)
+
+	logs, err := h.adminService.GetAuditLogs(userID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get audit logs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"logs": logs,
+	})
+}
+
+// ListProjects 查询所有项目，包括已隐藏的项目
+func (h *AdminHandler) ListProjects(c *gin.Context) {
+	limit, offset := adminPagination(c)
+
+	var userID int64
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		parsed, err := strconv.ParseInt(userIDStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID",
+			})
+			return
+		}
+		userID = parsed
+	}
+
+	projects, total, err := h.adminService.ListProjects(c.Query("q"), userID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get projects",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"projects": projects,
+		"total":    total,
+	})
+}
+
+// SetProjectVisibility 隐藏或恢复项目
+func (h *AdminHandler) SetProjectVisibility(c *gin.Context) {
+	projectID, ok := adminParamID(c, "Invalid project ID")
+	if !ok {
+		return
+	}
+
+	var req services.ProjectVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	err := h.adminService.SetProjectVisibility(c.GetInt64("user_id"), projectID, *req.IsPublic, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update project visibility",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Project visibility updated successfully",
+	})
+}
+
+// DeleteProject 删除任意项目
+func (h *AdminHandler) DeleteProject(c *gin.Context) {
+	projectID, ok := adminParamID(c, "Invalid project ID")
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.DeleteProject(c.GetInt64("user_id"), projectID, c.ClientIP()); err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete project",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Project deleted successfully",
+	})
+}
+
+// DeleteComment 删除任意评论及其回复
+func (h *AdminHandler) DeleteComment(c *gin.Context) {
+	commentID, ok := adminParamID(c, "Invalid comment ID")
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.DeleteComment(c.GetInt64("user_id"), commentID, c.ClientIP()); err != nil {
+		if errors.Is(err, services.ErrCommentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete comment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comment deleted successfully",
+	})
+}
+
+func adminParamID(c *gin.Context, message string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": message,
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+func adminPagination(c *gin.Context) (int, int) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
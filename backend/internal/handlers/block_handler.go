@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"devswipe-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BlockHandler struct {
+	blockService *services.BlockService
+}
+
+func NewBlockHandler() *BlockHandler {
+	return &BlockHandler{
+		blockService: services.NewBlockService(),
+	}
+}
+
+// BlockUser 拉黑用户
+func (h *BlockHandler) BlockUser(c *gin.Context) {
+	h.handleRelation(c, h.blockService.Block, "User blocked successfully")
+}
+
+// UnblockUser 取消拉黑
+func (h *BlockHandler) UnblockUser(c *gin.Context) {
+	h.handleRelation(c, h.blockService.Unblock, "User unblocked successfully")
+}
+
+// MuteUser 静音用户
+func (h *BlockHandler) MuteUser(c *gin.Context) {
+	h.handleRelation(c, h.blockService.Mute, "User muted successfully")
+}
+
+// UnmuteUser 取消静音
+func (h *BlockHandler) UnmuteUser(c *gin.Context) {
+	h.handleRelation(c, h.blockService.Unmute, "User unmuted successfully")
+}
+
+// ListBlocked 获取拉黑的用户列表
+func (h *BlockHandler) ListBlocked(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+	limit, offset := adminPagination(c)
+
+	users, err := h.blockService.GetBlockedUsers(userID.(int64), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get blocked users",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": users,
+	})
+}
+
+// ListMuted 获取静音的用户列表
+func (h *BlockHandler) ListMuted(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+	limit, offset := adminPagination(c)
+
+	users, err := h.blockService.GetMutedUsers(userID.(int64), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get muted users",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": users,
+	})
+}
+
+// handleRelation 解析目标用户ID，执行拉黑、静音操作并统一处理错误
+func (h *BlockHandler) handleRelation(c *gin.Context, action func(userID, targetID int64) error, message string) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	targetID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	if err := action(userID.(int64), targetID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, services.ErrBlockSelf), errors.Is(err, services.ErrMuteSelf),
+			errors.Is(err, services.ErrNotBlocked), errors.Is(err, services.ErrNotMuted):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update user relation",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"devswipe-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExperimentHandler struct {
+	experimentService *services.ExperimentService
+}
+
+func NewExperimentHandler() *ExperimentHandler {
+	return &ExperimentHandler{
+		experimentService: services.NewExperimentService(),
+	}
+}
+
+// GetReport 获取实验各变体的喜爱率和停留时长报告
+func (h *ExperimentHandler) GetReport(c *gin.Context) {
+	report, err := h.experimentService.GetReport(c.Param("name"))
+	if errors.Is(err, services.ErrExperimentNameRequired) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrExperimentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get experiment report",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
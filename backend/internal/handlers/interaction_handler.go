@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"devswipe-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InteractionHandler struct {
+	interactionService *services.InteractionService
+}
+
+func NewInteractionHandler() *InteractionHandler {
+	return &InteractionHandler{
+		interactionService: services.NewInteractionService(),
+	}
+}
+
+// BatchInteract 批量上报交互，用于离线缓存或快速滑动时合并请求
+func (h *InteractionHandler) BatchInteract(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var req services.BatchInteractionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	results, err := h.interactionService.ProcessBatch(userID.(int64), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to process interactions",
+		})
+		return
+	}
+
+	applied, failed := 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case services.BatchItemApplied:
+			applied++
+		case services.BatchItemFailed:
+			failed++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"applied": applied,
+		"failed":  failed,
+	})
+}
+
+// BatchInteractionCost 批量交互按条数计入限流：读取请求体中 items 的数量，并还原请求体供处理器绑定
+func BatchInteractionCost(c *gin.Context) int {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return 1
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var req struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return 1
+	}
+	return len(req.Items)
+}
+
+// GetMyInteractions 获取当前用户的交互记录，可按交互类型筛选
+func (h *InteractionHandler) GetMyInteractions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	interactions, err := h.interactionService.GetUserInteractions(userID.(int64), c.Query("type"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get interactions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"interactions": interactions,
+	})
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"devswipe-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFAHandler struct {
+	mfaService *services.MFAService
+}
+
+func NewMFAHandler() *MFAHandler {
+	return &MFAHandler{
+		mfaService: services.NewMFAService(),
+	}
+}
+
+// GetStatus 获取两步验证状态
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	status, err := h.mfaService.Status(userID.(int64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get two-factor status",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Enroll 生成两步验证密钥和 otpauth 地址
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	response, err := h.mfaService.Enroll(userID.(int64))
+	if err != nil {
+		if errors.Is(err, services.ErrMFAAlreadyEnabled) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to start two-factor enrolment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Confirm 提交验证码确认绑定，返回恢复码
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	userID, req, ok := bindMFACode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.mfaService.Confirm(userID, req.Code, c.ClientIP())
+	if err != nil {
+		respondMFAError(c, err, "Failed to enable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recovery_codes": codes,
+	})
+}
+
+// Disable 关闭两步验证
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, req, ok := bindMFACode(c)
+	if !ok {
+		return
+	}
+
+	if err := h.mfaService.Disable(userID, req.Code, c.ClientIP()); err != nil {
+		respondMFAError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, req, ok := bindMFACode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(userID, req.Code, c.ClientIP())
+	if err != nil {
+		respondMFAError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recovery_codes": codes,
+	})
+}
+
+func bindMFACode(c *gin.Context) (int64, *services.MFACodeRequest, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return 0, nil, false
+	}
+
+	var req services.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return 0, nil, false
+	}
+	return userID.(int64), &req, true
+}
+
+func respondMFAError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidMFACode),
+		errors.Is(err, services.ErrMFANotEnabled),
+		errors.Is(err, services.ErrMFANotEnrolled):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrMFAAlreadyEnabled):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"devswipe-backend/internal/services"
+	"devswipe-backend/pkg/oauth"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OAuthHandler struct {
+	oauthService *services.OAuthService
+}
+
+func NewOAuthHandler() *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: services.NewOAuthService(),
+	}
+}
+
+// Start 获取第三方登录的授权地址
+func (h *OAuthHandler) Start(c *gin.Context) {
+	h.start(c, 0)
+}
+
+// StartLink 已登录用户绑定新的第三方身份
+func (h *OAuthHandler) StartLink(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+	h.start(c, userID.(int64))
+}
+
+func (h *OAuthHandler) start(c *gin.Context, linkUserID int64) {
+	response, err := h.oauthService.Start(c.Param("provider"), linkUserID)
+	if err != nil {
+		if errors.Is(err, oauth.ErrUnknownProvider) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to start oauth login",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Callback 第三方授权完成后，用授权码登录或绑定
+func (h *OAuthHandler) Callback(c *gin.Context) {
+	var req services.OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.oauthService.Callback(c.Param("provider"), &req, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		switch {
+		case errors.Is(err, oauth.ErrUnknownProvider):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, services.ErrIdentityLinkedElsewhere):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, services.ErrInvalidOAuthState), errors.Is(err, services.ErrOAuthEmailRequired):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error": "OAuth login failed",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListIdentities 获取当前用户绑定的第三方身份
+func (h *OAuthHandler) ListIdentities(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	identities, err := h.oauthService.ListIdentities(userID.(int64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get identities",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"identities": identities,
+	})
+}
+
+// Unlink 解绑第三方身份
+func (h *OAuthHandler) Unlink(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	err := h.oauthService.Unlink(userID.(int64), c.Param("provider"), c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrIdentityNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, services.ErrLastLoginMethod):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to unlink identity",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Identity unlinked successfully",
+	})
+}
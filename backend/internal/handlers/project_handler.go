@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"devswipe-backend/internal/services"
 
@@ -12,12 +15,20 @@ import (
 type ProjectHandler struct {
 	projectService     *services.ProjectService
 	interactionService *services.InteractionService
+	experimentService  *services.ExperimentService
+	trendingService    *services.TrendingService
+	followingService   *services.FollowingFeedService
+	blockService       *services.BlockService
 }
 
 func NewProjectHandler() *ProjectHandler {
 	return &ProjectHandler{
 		projectService:     services.NewProjectService(),
 		interactionService: services.NewInteractionService(),
+		experimentService:  services.NewExperimentService(),
+		trendingService:    services.NewTrendingService(),
+		followingService:   services.NewFollowingFeedService(),
+		blockService:       services.NewBlockService(),
 	}
 }
 
@@ -41,6 +52,12 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 	}
 
 	project, err := h.projectService.CreateProject(userID.(int64), &req)
+	if errors.Is(err, services.ErrEmailNotVerified) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
@@ -70,6 +87,16 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 		return
 	}
 
+	// 与作者互相拉黑时项目对查看者不可见
+	if viewerID, exists := c.Get("user_id"); exists {
+		if err := h.blockService.CheckInteraction(viewerID.(int64), project.UserID); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Project not found",
+			})
+			return
+		}
+	}
+
 	// 增加浏览次数
 	h.projectService.IncrementViewCount(projectID)
 
@@ -170,11 +197,15 @@ func (h *ProjectHandler) GetUserProjects(c *gin.Context) {
 		offset = 0
 	}
 
-	projects, err := h.projectService.GetUserProjects(userID, limit, offset)
+	viewerID, _ := c.Get("user_id") // 可选认证
+	var viewerIDInt int64
+	if viewerID != nil {
+		viewerIDInt = viewerID.(int64)
+	}
+
+	projects, err := h.projectService.GetUserProjects(viewerIDInt, userID, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get user projects",
-		})
+		respondProfileAccessError(c, err, "Failed to get user projects")
 		return
 	}
 
@@ -205,12 +236,17 @@ func (h *ProjectHandler) GetFeed(c *gin.Context) {
 		Limit:     limit,
 		SessionID: c.Query("session_id"),
 	}
+	if tags := c.Query("tags"); tags != "" {
+		params.Tags = strings.Split(tags, ",")
+	}
 
 	var userIDInt int64
 	if userID != nil {
 		userIDInt = userID.(int64)
 	}
 
+	params.Assignment = h.experimentService.Assign(userIDInt, params.SessionID)
+
 	projects, err := h.projectService.GetUserFeed(userIDInt, params)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -219,11 +255,96 @@ func (h *ProjectHandler) GetFeed(c *gin.Context) {
 		return
 	}
 
+	// 记录实验曝光
+	if err := h.experimentService.LogExposure(params.Assignment, userIDInt, params.SessionID); err != nil {
+		log.Printf("Failed to log experiment exposure: %v", err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"projects":   projects,
 		"has_more":   len(projects) == limit,
 		"page":       page,
 		"session_id": params.SessionID,
+		"experiment": params.Assignment,
+	})
+}
+
+// GetFollowingFeed 获取关注的作者发布的新项目和项目更新
+func (h *ProjectHandler) GetFollowingFeed(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	page, err := h.followingService.GetFeed(userID.(int64), c.Query("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid cursor",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get following feed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetTrending 获取热门项目榜单
+func (h *ProjectHandler) GetTrending(c *gin.Context) {
+	window := c.DefaultQuery("window", "day")
+	if _, ok := services.TrendingWindows[window]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid window, must be one of day, week, month",
+		})
+		return
+	}
+
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	viewerID, _ := c.Get("user_id") // 可选认证
+	var viewerIDInt int64
+	if viewerID != nil {
+		viewerIDInt = viewerID.(int64)
+	}
+
+	tag := c.Query("tag")
+	projects, hasMore, err := h.trendingService.GetTrending(viewerIDInt, window, tag, limit, (page-1)*limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get trending projects",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"projects": projects,
+		"window":   window,
+		"tag":      tag,
+		"page":     page,
+		"has_more": hasMore,
 	})
 }
 
@@ -300,6 +421,12 @@ func (h *ProjectHandler) AddComment(c *gin.Context) {
 
 	comment, err := h.interactionService.AddComment(userID.(int64), &req)
 	if err != nil {
+		if errors.Is(err, services.ErrUserBlocked) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 		})
@@ -333,7 +460,13 @@ func (h *ProjectHandler) GetComments(c *gin.Context) {
 		offset = 0
 	}
 
-	comments, err := h.interactionService.GetProjectComments(projectID, limit, offset)
+	viewerID, _ := c.Get("user_id") // 可选认证
+	var viewerIDInt int64
+	if viewerID != nil {
+		viewerIDInt = viewerID.(int64)
+	}
+
+	comments, err := h.interactionService.GetProjectComments(viewerIDInt, projectID, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to get comments",
@@ -391,7 +524,13 @@ func (h *ProjectHandler) SearchProjects(c *gin.Context) {
 		offset = 0
 	}
 
-	projects, err := h.projectService.SearchProjects(keyword, limit, offset)
+	viewerID, _ := c.Get("user_id") // 可选认证
+	var viewerIDInt int64
+	if viewerID != nil {
+		viewerIDInt = viewerID.(int64)
+	}
+
+	projects, err := h.projectService.SearchProjects(viewerIDInt, keyword, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to search projects",
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"devswipe-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	tagService *services.TagService
+}
+
+func NewTagHandler() *TagHandler {
+	return &TagHandler{
+		tagService: services.NewTagService(),
+	}
+}
+
+// SuggestTags 标签自动补全，按前缀匹配并按使用次数排序
+func (h *TagHandler) SuggestTags(c *gin.Context) {
+	tags, err := h.tagService.Suggest(c.Query("prefix"), tagLimit(c, 10))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get tag suggestions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tags": tags,
+	})
+}
+
+// GetPopularTags 获取热门标签及其项目数，可按类型过滤
+func (h *TagHandler) GetPopularTags(c *gin.Context) {
+	tags, err := h.tagService.Popular(c.Query("type"), tagLimit(c, 30))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTagType) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get popular tags",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tags": tags,
+	})
+}
+
+// GetTag 获取标签详情：描述、相关标签和热门项目
+func (h *TagHandler) GetTag(c *gin.Context) {
+	viewerID, _ := c.Get("user_id") // 可选认证
+	var viewerIDInt int64
+	if viewerID != nil {
+		viewerIDInt = viewerID.(int64)
+	}
+
+	page, err := h.tagService.GetTagPage(viewerIDInt, c.Param("name"))
+	if err != nil {
+		if errors.Is(err, services.ErrTagNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get tag",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// ListTags 按关键字和类型查询标签词表
+func (h *TagHandler) ListTags(c *gin.Context) {
+	limit, offset := adminPagination(c)
+
+	tags, total, err := h.tagService.ListTags(c.Query("q"), c.Query("type"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get tags",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tags":  tags,
+		"total": total,
+	})
+}
+
+// UpdateTag 修改标签的规范写法、类型或描述
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	tagID, ok := adminParamID(c, "Invalid tag ID")
+	if !ok {
+		return
+	}
+
+	var req services.UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	tag, err := h.tagService.UpdateTag(c.GetInt64("user_id"), tagID, &req, c.ClientIP())
+	if err != nil {
+		respondTagError(c, err, "Failed to update tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// AddSynonym 为标签添加同义词
+func (h *TagHandler) AddSynonym(c *gin.Context) {
+	tagID, ok := adminParamID(c, "Invalid tag ID")
+	if !ok {
+		return
+	}
+
+	var req services.TagSynonymRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.tagService.AddSynonym(c.GetInt64("user_id"), tagID, req.Synonym, c.ClientIP()); err != nil {
+		respondTagError(c, err, "Failed to add synonym")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Synonym added successfully",
+	})
+}
+
+// RemoveSynonym 删除标签的同义词
+func (h *TagHandler) RemoveSynonym(c *gin.Context) {
+	tagID, ok := adminParamID(c, "Invalid tag ID")
+	if !ok {
+		return
+	}
+
+	if err := h.tagService.RemoveSynonym(c.GetInt64("user_id"), tagID, c.Param("synonym"), c.ClientIP()); err != nil {
+		respondTagError(c, err, "Failed to remove synonym")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Synonym removed successfully",
+	})
+}
+
+// MergeTags 将标签合并到另一个标签
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	sourceID, ok := adminParamID(c, "Invalid tag ID")
+	if !ok {
+		return
+	}
+
+	var req services.MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	tag, err := h.tagService.MergeTags(c.GetInt64("user_id"), sourceID, req.TargetID, c.ClientIP())
+	if err != nil {
+		respondTagError(c, err, "Failed to merge tags")
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// respondTagError 将标签维护的错误映射为HTTP状态码
+func respondTagError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrTagNotFound), errors.Is(err, services.ErrTagSynonymNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrTagConflict):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrEmptyTagName), errors.Is(err, services.ErrMergeSameTag):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
+
+// tagLimit 解析公开标签接口的数量参数，最多返回50个
+func tagLimit(c *gin.Context, defaultLimit int) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 || limit > 50 {
+		return defaultLimit
+	}
+	return limit
+}
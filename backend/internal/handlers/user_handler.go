@@ -1,7 +1,13 @@
 package handlers
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"path"
 	"strconv"
 
 	"devswipe-backend/internal/services"
@@ -10,12 +16,20 @@ import (
 )
 
 type UserHandler struct {
-	userService *services.UserService
+	userService       *services.UserService
+	preferenceService *services.PreferenceService
+	accountService    *services.AccountService
+	exportService     *services.DataExportService
+	deletionService   *services.AccountDeletionService
 }
 
 func NewUserHandler() *UserHandler {
 	return &UserHandler{
-		userService: services.NewUserService(),
+		userService:       services.NewUserService(),
+		preferenceService: services.NewPreferenceService(),
+		accountService:    services.NewAccountService(),
+		exportService:     services.NewDataExportService(),
+		deletionService:   services.NewAccountDeletionService(),
 	}
 }
 
@@ -30,7 +44,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	response, err := h.userService.Register(&req)
+	response, err := h.userService.Register(&req, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
@@ -52,8 +66,16 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response, err := h.userService.Login(&req)
+	response, err := h.userService.Login(&req, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
+		var locked *services.LoginLockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": err.Error(),
 		})
@@ -63,6 +85,174 @@ func (h *UserHandler) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// LoginMFA 登录的第二步：提交两步验证码换取令牌
+func (h *UserHandler) LoginMFA(c *gin.Context) {
+	var req services.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.userService.LoginMFA(&req, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		var locked *services.LoginLockedError
+		switch {
+		case errors.As(err, &locked):
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, services.ErrInvalidMFAToken), errors.Is(err, services.ErrInvalidMFACode):
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to complete login",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UnlockAccount 使用解锁令牌解除账户锁定
+func (h *UserHandler) UnlockAccount(c *gin.Context) {
+	var req services.UnlockAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.userService.UnlockAccount(&req, c.ClientIP()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account unlocked successfully",
+	})
+}
+
+// ForgotPassword 发送密码重置邮件
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req services.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to send password reset email",
+		})
+		return
+	}
+
+	// 无论邮箱是否存在都返回相同的响应
+	c.JSON(http.StatusOK, gin.H{
+		"message": "If the email exists, a password reset link has been sent",
+	})
+}
+
+// ResetPassword 使用重置令牌设置新密码
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req services.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.accountService.ResetPassword(&req); err != nil {
+		if errors.Is(err, services.ErrInvalidAccountToken) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to reset password",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password reset successfully",
+	})
+}
+
+// VerifyEmail 使用验证令牌确认邮箱
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req services.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(req.Token); err != nil {
+		if errors.Is(err, services.ErrInvalidAccountToken) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to verify email",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email verified successfully",
+	})
+}
+
+// ResendVerificationEmail 重新发送邮箱验证邮件
+func (h *UserHandler) ResendVerificationEmail(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.accountService.SendVerificationEmail(userID.(int64)); err != nil {
+		if errors.Is(err, services.ErrEmailAlreadyVerified) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to send verification email",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Verification email sent",
+	})
+}
+
 // GetProfile 获取用户资料
 func (h *UserHandler) GetProfile(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -94,27 +284,345 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	var updates map[string]interface{}
-	if err := c.ShouldBindJSON(&updates); err != nil {
+	var req services.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.userService.UpdateUserProfile(userID.(int64), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUsernameTaken):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, services.ErrInvalidUsername), errors.Is(err, services.ErrInvalidAvatarURL),
+			errors.Is(err, services.ErrInvalidWebsite), errors.Is(err, services.ErrInvalidGitHubHandle):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update profile",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Profile updated successfully",
+		"user":    user,
+	})
+}
+
+// ExportData 下载当前用户的全部个人数据，format=zip 时按部分打包，默认为单个JSON文件
+func (h *UserHandler) ExportData(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request data",
+			"error": "Invalid format, must be json or zip",
 		})
 		return
 	}
 
-	err := h.userService.UpdateUserProfile(userID.(int64), updates)
+	export, err := h.exportService.Export(userID.(int64))
 	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to export data",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("devswipe-export-%d-%s.%s", userID.(int64), export.ExportedAt.Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if format == "json" {
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteZip(&buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to export data",
+		})
+		return
+	}
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// DeleteAccount 申请注销账户，冷静期结束后删除所有数据
+func (h *UserHandler) DeleteAccount(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var req services.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	deletionAt, err := h.deletionService.RequestDeletion(userID.(int64), &req, c.GetString("session_id"), c.ClientIP())
+	if err != nil {
+		if errors.Is(err, services.ErrDeletionConfirmation) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete account",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":     "Account scheduled for deletion",
+		"deletion_at": deletionAt,
+	})
+}
+
+// CancelDeletion 撤销注销申请
+func (h *UserHandler) CancelDeletion(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.deletionService.CancelDeletion(userID.(int64), c.ClientIP()); err != nil {
+		if errors.Is(err, services.ErrDeletionNotRequested) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to cancel account deletion",
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Profile updated successfully",
+		"message": "Account deletion cancelled",
 	})
 }
 
+// UpdatePrivacy 更新个人主页的隐私设置
+func (h *UserHandler) UpdatePrivacy(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var req services.UpdatePrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.userService.UpdatePrivacy(userID.(int64), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update privacy settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// GetUser 获取用户的公开主页，登录用户可以看到与对方的关注关系
+func (h *UserHandler) GetUser(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	viewerID, _ := c.Get("user_id") // 可选认证
+	var viewerIDInt int64
+	if viewerID != nil {
+		viewerIDInt = viewerID.(int64)
+	}
+
+	profile, err := h.userService.GetPublicProfile(viewerIDInt, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get user",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// GetUserByUsername 按用户名获取用户主页，旧用户名永久重定向到改名后的地址
+func (h *UserHandler) GetUserByUsername(c *gin.Context) {
+	user, renamed, err := h.userService.ResolveUsername(c.Param("username"))
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get user",
+		})
+		return
+	}
+
+	if renamed {
+		location := path.Join(path.Dir(c.Request.URL.Path), url.PathEscape(user.Username))
+		if c.Request.URL.RawQuery != "" {
+			location += "?" + c.Request.URL.RawQuery
+		}
+		c.Redirect(http.StatusMovedPermanently, location)
+		return
+	}
+
+	viewerID, _ := c.Get("user_id") // 可选认证
+	var viewerIDInt int64
+	if viewerID != nil {
+		viewerIDInt = viewerID.(int64)
+	}
+
+	profile, err := h.userService.GetPublicProfile(viewerIDInt, user.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get user",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// SearchUsers 按用户名、简介和技术栈搜索用户
+func (h *UserHandler) SearchUsers(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 50 {
+		limit = 20
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	viewerID, _ := c.Get("user_id") // 可选认证
+	var viewerIDInt int64
+	if viewerID != nil {
+		viewerIDInt = viewerID.(int64)
+	}
+
+	users, err := h.userService.SearchUsers(viewerIDInt, c.Query("q"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to search users",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": users,
+	})
+}
+
+// GetPreferences 获取用户推荐偏好
+func (h *UserHandler) GetPreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	preferences, err := h.preferenceService.GetPreferences(userID.(int64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get preferences",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences 更新用户推荐偏好（关注/屏蔽标签、偏好阶段）
+func (h *UserHandler) UpdatePreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var req services.UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	preferences, err := h.preferenceService.UpdatePreferences(userID.(int64), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
 // FollowUser 关注用户
 func (h *UserHandler) FollowUser(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -136,6 +644,12 @@ func (h *UserHandler) FollowUser(c *gin.Context) {
 
 	err = h.userService.FollowUser(userID.(int64), followingID)
 	if err != nil {
+		if errors.Is(err, services.ErrUserBlocked) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 		})
@@ -203,11 +717,15 @@ func (h *UserHandler) GetFollowers(c *gin.Context) {
 		offset = 0
 	}
 
-	followers, err := h.userService.GetFollowers(userID, limit, offset)
+	viewerID, _ := c.Get("user_id") // 可选认证
+	var viewerIDInt int64
+	if viewerID != nil {
+		viewerIDInt = viewerID.(int64)
+	}
+
+	followers, err := h.userService.GetFollowers(viewerIDInt, userID, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get followers",
-		})
+		respondProfileAccessError(c, err, "Failed to get followers")
 		return
 	}
 
@@ -240,11 +758,15 @@ func (h *UserHandler) GetFollowing(c *gin.Context) {
 		offset = 0
 	}
 
-	following, err := h.userService.GetFollowing(userID, limit, offset)
+	viewerID, _ := c.Get("user_id") // 可选认证
+	var viewerIDInt int64
+	if viewerID != nil {
+		viewerIDInt = viewerID.(int64)
+	}
+
+	following, err := h.userService.GetFollowing(viewerIDInt, userID, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get following",
-		})
+		respondProfileAccessError(c, err, "Failed to get following")
 		return
 	}
 
@@ -252,3 +774,21 @@ func (h *UserHandler) GetFollowing(c *gin.Context) {
 		"following": following,
 	})
 }
+
+// respondProfileAccessError 将主页访问限制映射为HTTP状态码：不可见的用户返回404，受隐私设置限制返回403
+func respondProfileAccessError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrProfileRestricted):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
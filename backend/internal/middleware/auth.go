@@ -1,15 +1,19 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
+	"devswipe-backend/internal/services"
 	"devswipe-backend/pkg/auth"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware 校验JWT或个人访问令牌。个人访问令牌只能访问声明了权限范围的接口，
+// 且必须拥有 scopes 中的全部权限范围；不传 scopes 时只接受JWT
+func AuthMiddleware(scopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -31,6 +35,11 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		token := tokenParts[1]
+		if auth.IsAccessToken(token) {
+			authenticateAccessToken(c, token, scopes)
+			return
+		}
+
 		claims, err := auth.ValidateToken(token)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -40,15 +49,77 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 会话被撤销或退出所有设备后，未过期的JWT同样失效
+		if err := services.NewSessionService().Validate(claims.UserID, claims.ID, c.ClientIP()); err != nil {
+			if errors.Is(err, services.ErrSessionRevoked) {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": err.Error(),
+				})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to validate session",
+				})
+			}
+			c.Abort()
+			return
+		}
+
 		// 将用户信息存储到上下文中
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
+		c.Set("session_id", claims.ID)
 
 		c.Next()
 	}
 }
 
+// authenticateAccessToken 校验个人访问令牌及其权限范围，角色以用户当前的角色为准
+func authenticateAccessToken(c *gin.Context, raw string, scopes []string) {
+	if len(scopes) == 0 {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Personal access tokens cannot be used for this endpoint",
+		})
+		c.Abort()
+		return
+	}
+
+	token, user, err := services.NewAccessTokenService().Authenticate(raw, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAccessToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid token",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to validate token",
+			})
+		}
+		c.Abort()
+		return
+	}
+
+	for _, scope := range scopes {
+		if !token.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":          "Insufficient token scope",
+				"required_scope": scope,
+			})
+			c.Abort()
+			return
+		}
+	}
+
+	c.Set("user_id", user.ID)
+	c.Set("username", user.Username)
+	c.Set("email", user.Email)
+	c.Set("role", user.Role)
+	c.Set("access_token_id", token.ID)
+
+	c.Next()
+}
+
 func OptionalAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -69,11 +140,50 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 			c.Next()
 			return
 		}
+		if err := services.NewSessionService().Validate(claims.UserID, claims.ID, c.ClientIP()); err != nil {
+			c.Next()
+			return
+		}
 
 		// 将用户信息存储到上下文中
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
+		c.Set("session_id", claims.ID)
+
+		c.Next()
+	}
+}
+
+// RequireRole 要求当前用户的角色不低于指定角色之一，需要放在 AuthMiddleware 之后
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, required := range roles {
+			if auth.RoleAtLeast(role, required) {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Insufficient role",
+		})
+		c.Abort()
+	}
+}
+
+// RequirePermission 要求当前用户的角色拥有指定权限，需要放在 AuthMiddleware 之后
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.HasPermission(c.GetString("role"), permission) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Permission denied",
+			})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"devswipe-backend/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serveAsRole 以指定角色请求挂了 handlers 的路由，返回状态码
+func serveAsRole(role string, handlers ...gin.HandlerFunc) int {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	chain := append([]gin.HandlerFunc{func(c *gin.Context) {
+		c.Set("role", role)
+	}}, handlers...)
+	chain = append(chain, func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/", chain...)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	return w.Code
+}
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		role string
+		want int
+	}{
+		{auth.RoleUser, http.StatusForbidden},
+		{auth.RoleCreator, http.StatusForbidden},
+		{auth.RoleModerator, http.StatusNoContent},
+		{auth.RoleAdmin, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		if got := serveAsRole(tt.role, RequirePermission(auth.PermModerateProjects)); got != tt.want {
+			t.Errorf("RequirePermission(%s) as %q = %d, want %d", auth.PermModerateProjects, tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		role string
+		want int
+	}{
+		{auth.RoleUser, http.StatusForbidden},
+		{auth.RoleCreator, http.StatusForbidden},
+		{auth.RoleModerator, http.StatusNoContent},
+		{auth.RoleAdmin, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		if got := serveAsRole(tt.role, RequireRole(auth.RoleModerator)); got != tt.want {
+			t.Errorf("RequireRole(%s) as %q = %d, want %d", auth.RoleModerator, tt.role, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"devswipe-backend/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	idempotencyTTL            = 24 * time.Hour
+	idempotencyMaxKeyLength   = 255
+	idempotencyStateRunning   = "processing"
+	idempotencyStateCompleted = "completed"
+)
+
+// idempotencyRecord 保存在Redis中的请求记录
+type idempotencyRecord struct {
+	State       string `json:"state"`
+	Fingerprint string `json:"fingerprint"` // 方法、路径和请求体的摘要
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// idempotencyWriter 记录响应体以便重放
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware 支持 Idempotency-Key 请求头：首次响应（状态码和响应体）在Redis中保存24小时，
+// 相同的键再次请求时直接重放；相同的键配合不同的请求体会被拒绝。
+// 需要放在认证中间件之后，键按用户隔离；只读请求和未认证的请求不处理，避免匿名请求之间共享响应。
+// 应放在限流中间件之前，重放的请求不再消耗限流配额。
+func IdempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		userID, authenticated := c.Get("user_id")
+		if key == "" || !authenticated || isSafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if len(key) > idempotencyMaxKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Idempotency-Key is too long",
+			})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Failed to read request body",
+			})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		cacheKey := fmt.Sprintf("idempotency:%v:%s", userID, key)
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		ctx := context.Background()
+		cacheManager := cache.NewCacheManager()
+
+		// 先占位，防止并发的重复请求同时执行
+		acquired, err := cacheManager.SetNX(ctx, cacheKey, idempotencyRecord{
+			State:       idempotencyStateRunning,
+			Fingerprint: fingerprint,
+		}, idempotencyTTL)
+		if err != nil {
+			// Redis不可用时不阻塞请求
+			log.Printf("Idempotency check failed: %v", err)
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			var record idempotencyRecord
+			if err := cacheManager.Get(ctx, cacheKey, &record); err != nil {
+				c.JSON(http.StatusConflict, gin.H{
+					"error": "A request with this Idempotency-Key is being processed",
+				})
+				c.Abort()
+				return
+			}
+
+			switch {
+			case record.Fingerprint != fingerprint:
+				c.JSON(http.StatusUnprocessableEntity, gin.H{
+					"error": "Idempotency-Key has already been used with a different request",
+				})
+			case record.State != idempotencyStateCompleted:
+				c.JSON(http.StatusConflict, gin.H{
+					"error": "A request with this Idempotency-Key is being processed",
+				})
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				c.Data(record.Status, record.ContentType, record.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		// 服务端错误和被限流的请求允许客户端使用同一个键重试
+		status := writer.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			if err := cacheManager.Delete(ctx, cacheKey); err != nil {
+				log.Printf("Failed to release idempotency key: %v", err)
+			}
+			return
+		}
+
+		err = cacheManager.Set(ctx, cacheKey, idempotencyRecord{
+			State:       idempotencyStateCompleted,
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		}, idempotencyTTL)
+		if err != nil {
+			log.Printf("Failed to store idempotent response: %v", err)
+		}
+	}
+}
+
+// isSafeMethod 只读的HTTP方法本身就是幂等的
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func requestFingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"devswipe-backend/internal/config"
+	"devswipe-backend/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitMiddleware 按路由限流，同时检查IP和登录用户两个维度，任一维度超限即返回429（各维度都不计数）。
+// 按用户限流时需要放在认证中间件之后。响应中带有 RateLimit-* 头，被拒绝时带 Retry-After。
+func RateLimitMiddleware(route string, rule config.RateLimitRule) gin.HandlerFunc {
+	return WeightedRateLimitMiddleware(route, rule, nil)
+}
+
+// WeightedRateLimitMiddleware 与 RateLimitMiddleware 相同，但每个请求按 cost 返回的次数计数，
+// 用于一次请求包含多个操作的批量接口。cost 为 nil 或返回值小于1时按1次计
+func WeightedRateLimitMiddleware(route string, rule config.RateLimitRule, cost func(*gin.Context) int) gin.HandlerFunc {
+	window := time.Duration(rule.Window) * time.Second
+
+	return func(c *gin.Context) {
+		if !config.AppConfig.RateLimit.Enabled || window <= 0 {
+			c.Next()
+			return
+		}
+
+		var keys []cache.RateLimitKey
+		if rule.PerIP > 0 {
+			keys = append(keys, cache.RateLimitKey{Key: fmt.Sprintf("rate_limit:%s:ip:%s", route, c.ClientIP()), Limit: rule.PerIP})
+		}
+		if userID, exists := c.Get("user_id"); exists && rule.PerUser > 0 {
+			keys = append(keys, cache.RateLimitKey{Key: fmt.Sprintf("rate_limit:%s:user:%v", route, userID), Limit: rule.PerUser})
+		}
+		if len(keys) == 0 {
+			c.Next()
+			return
+		}
+
+		weight := 1
+		if cost != nil {
+			weight = max(cost(c), 1)
+		}
+
+		allowed, results, err := cache.NewCacheManager().AllowSlidingWindow(context.Background(), keys, weight, window)
+		if err != nil {
+			// Redis不可用时放行，避免限流器成为单点故障
+			log.Printf("Rate limit check failed: %v", err)
+			c.Next()
+			return
+		}
+
+		if !allowed {
+			for _, result := range results {
+				if !result.Allowed {
+					setRateLimitHeaders(c, result, window)
+					c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.Reset)))
+					break
+				}
+			}
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": "Too many requests, please try again later",
+			})
+			c.Abort()
+			return
+		}
+
+		// 剩余次数最少的维度作为响应头
+		tightest := results[0]
+		for _, result := range results[1:] {
+			if result.Remaining < tightest.Remaining {
+				tightest = result
+			}
+		}
+		setRateLimitHeaders(c, tightest, window)
+
+		c.Next()
+	}
+}
+
+// setRateLimitHeaders 按 IETF RateLimit 头字段草案输出限额信息
+func setRateLimitHeaders(c *gin.Context, result *cache.RateLimitResult, window time.Duration) {
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", result.Limit, int(window.Seconds())))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// PersonalAccessToken 用户为脚本和CI创建的长期令牌，只保存令牌的哈希
+type PersonalAccessToken struct {
+	ID         int64      `json:"id" gorm:"primaryKey"`
+	UserID     int64      `json:"user_id" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"size:100;not null"`
+	Prefix     string     `json:"prefix" gorm:"size:20"` // 令牌的前几位，用于在列表中辨认
+	TokenHash  string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	Scopes     string     `json:"scopes" gorm:"size:255"` // 逗号分隔，如 projects:write,interactions:read
+	ExpiresAt  *time.Time `json:"expires_at"`             // 为空表示永不过期
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip" gorm:"size:45"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ScopeList 返回令牌的权限范围
+func (t *PersonalAccessToken) ScopeList() []string {
+	if t.Scopes == "" {
+		return nil
+	}
+	return strings.Split(t.Scopes, ",")
+}
+
+// HasScope 判断令牌是否拥有某个权限范围
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// TableName 指定表名
+func (PersonalAccessToken) TableName() string {
+	return "personal_access_tokens"
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditLog 安全相关事件的审计日志，如账户锁定、解锁
+type AuditLog struct {
+	ID        int64     `json:"id" gorm:"primaryKey"`
+	UserID    *int64    `json:"user_id" gorm:"index"` // 针对IP的事件为空
+	Event     string    `json:"event" gorm:"size:50;not null;index"`
+	IP        string    `json:"ip" gorm:"size:64"`
+	Detail    string    `json:"detail" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName 指定表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
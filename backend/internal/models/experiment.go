@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// ExperimentExposure 记录一次实验曝光（浏览流响应）
+type ExperimentExposure struct {
+	ID         int64     `json:"id" gorm:"primaryKey"`
+	Experiment string    `json:"experiment" gorm:"size:50;not null;index:idx_experiment_variant"`
+	Variant    string    `json:"variant" gorm:"size:50;not null;index:idx_experiment_variant"`
+	UnitID     string    `json:"unit_id" gorm:"size:100;not null"` // 分桶单位：用户ID或会话ID
+	UserID     int64     `json:"user_id" gorm:"index"`
+	SessionID  string    `json:"session_id" gorm:"size:100;index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -5,16 +5,20 @@ import (
 )
 
 type UserInteraction struct {
-	ID                 int64     `json:"id" gorm:"primaryKey"`
-	UserID             int64     `json:"user_id" gorm:"not null"`
-	ProjectID          int64     `json:"project_id" gorm:"not null"`
-	InteractionType    string    `json:"interaction_type" gorm:"size:20;not null"` // like, dislike, super_like, skip, bookmark
-	StructuredFeedback string    `json:"structured_feedback" gorm:"size:50"`       // not_interested, unclear_problem, easy_tech, existing_products, poor_demo
-	SessionID          string    `json:"session_id" gorm:"size:100"`
-	ViewDuration       float64   `json:"view_duration"` // 观看时长（秒）
-	CreatedAt          time.Time `json:"created_at"`
+	ID                 int64      `json:"id" gorm:"primaryKey"`
+	UserID             int64      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_idempotency_key"`
+	ProjectID          int64      `json:"project_id" gorm:"not null"`
+	InteractionType    string     `json:"interaction_type" gorm:"size:20;not null"` // like, dislike, super_like, skip, bookmark
+	StructuredFeedback string     `json:"structured_feedback" gorm:"size:50"`       // not_interested, unclear_problem, easy_tech, existing_products, poor_demo
+	SessionID          string     `json:"session_id" gorm:"size:100"`
+	ViewDuration       float64    `json:"view_duration"`                                                                 // 观看时长（秒）
+	Experiment         string     `json:"experiment,omitempty" gorm:"size:50;index"`                                     // 交互发生时所在的A/B实验
+	Variant            string     `json:"variant,omitempty" gorm:"size:50"`                                              // 实验变体
+	IdempotencyKey     *string    `json:"idempotency_key,omitempty" gorm:"size:64;uniqueIndex:idx_user_idempotency_key"` // 客户端生成，批量上报重试时去重
+	ClientCreatedAt    *time.Time `json:"client_created_at,omitempty"`                                                   // 客户端记录的滑动时间
+	CreatedAt          time.Time  `json:"created_at"`
 
-	User    User    `json:"user" gorm:"foreignKey:UserID"`
+	User    Author  `json:"user" gorm:"foreignKey:UserID"`
 	Project Project `json:"project" gorm:"foreignKey:ProjectID"`
 
 	// 确保一个用户对一个项目只能有一种交互类型
@@ -32,7 +36,7 @@ type Comment struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 
-	User    User      `json:"user" gorm:"foreignKey:UserID"`
+	User    Author    `json:"user" gorm:"foreignKey:UserID"`
 	Project Project   `json:"project" gorm:"foreignKey:ProjectID"`
 	Parent  *Comment  `json:"parent,omitempty" gorm:"foreignKey:ParentID"`
 	Replies []Comment `json:"replies,omitempty" gorm:"foreignKey:ParentID"`
@@ -48,7 +52,7 @@ type Collection struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 
-	User  User             `json:"user" gorm:"foreignKey:UserID"`
+	User  Author           `json:"user" gorm:"foreignKey:UserID"`
 	Items []CollectionItem `json:"items,omitempty" gorm:"foreignKey:CollectionID"`
 }
 
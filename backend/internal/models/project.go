@@ -25,7 +25,7 @@ type Project struct {
 	UpdatedAt      time.Time `json:"updated_at"`
 
 	// 关联字段
-	User            User              `json:"user" gorm:"foreignKey:UserID"`
+	User            Author            `json:"user" gorm:"foreignKey:UserID"`
 	Tags            []ProjectTag      `json:"tags" gorm:"foreignKey:ProjectID"`
 	Interactions    []UserInteraction `json:"interactions,omitempty" gorm:"foreignKey:ProjectID"`
 	Comments        []Comment         `json:"comments,omitempty" gorm:"foreignKey:ProjectID"`
@@ -35,7 +35,8 @@ type Project struct {
 type ProjectTag struct {
 	ID        int64     `json:"id" gorm:"primaryKey"`
 	ProjectID int64     `json:"project_id" gorm:"not null"`
-	TagName   string    `json:"tag_name" gorm:"size:50;not null"`
+	TagID     int64     `json:"tag_id" gorm:"index"`              // 指向 tags 表中的规范标签
+	TagName   string    `json:"tag_name" gorm:"size:50;not null"` // 规范标签名的副本，标签改名、合并时同步更新
 	TagType   string    `json:"tag_type" gorm:"size:20;not null"` // tech, domain, function, stage, hackathon
 	CreatedAt time.Time `json:"created_at"`
 
@@ -45,6 +46,28 @@ type ProjectTag struct {
 	// UniqueConstraint struct{} `gorm:"uniqueIndex:idx_project_tag,unique"`
 }
 
+// ProjectBanditStats 项目探索（多臂老虎机）统计，用于Thompson采样
+type ProjectBanditStats struct {
+	ID          int64     `json:"id" gorm:"primaryKey"`
+	ProjectID   int64     `json:"project_id" gorm:"uniqueIndex;not null"`
+	Impressions int       `json:"impressions" gorm:"default:0"` // 在浏览流中的曝光次数
+	Successes   int       `json:"successes" gorm:"default:0"`   // like / super_like
+	Failures    int       `json:"failures" gorm:"default:0"`    // dislike / skip
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ProjectUpdate 作者对已发布项目的更新记录，出现在关注者的动态流中
+type ProjectUpdate struct {
+	ID        int64     `json:"id" gorm:"primaryKey"`
+	ProjectID int64     `json:"project_id" gorm:"not null;index"`
+	UserID    int64     `json:"user_id" gorm:"not null;index"`
+	Summary   string    `json:"summary" gorm:"size:255"` // 变更的字段，逗号分隔
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	Project Project `json:"project" gorm:"foreignKey:ProjectID"`
+}
+
 type ProjectStats struct {
 	ProjectID      int64   `json:"project_id"`
 	LikeRate       float64 `json:"like_rate"`
@@ -60,3 +83,13 @@ type ProjectStats struct {
 func (ProjectTag) TableName() string {
 	return "project_tags"
 }
+
+// TableName 指定表名
+func (ProjectBanditStats) TableName() string {
+	return "project_bandit_stats"
+}
+
+// TableName 指定表名
+func (ProjectUpdate) TableName() string {
+	return "project_updates"
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// SigningKey JWT的非对称签名密钥，保存在数据库中供所有实例共享。
+// 新密钥在 ActivatesAt 之前只用于校验，保证各实例都已加载后才开始签名
+type SigningKey struct {
+	ID          int64      `json:"id" gorm:"primaryKey"`
+	Kid         string     `json:"kid" gorm:"size:64;uniqueIndex;not null"`
+	Algorithm   string     `json:"algorithm" gorm:"size:10;not null"`
+	PrivateKey  string     `json:"-" gorm:"type:text;not null"` // PKCS#8 PEM
+	ActivatesAt time.Time  `json:"activates_at" gorm:"index"`
+	ExpiresAt   *time.Time `json:"expires_at"` // 被替换后，用它签发的令牌全部过期的时间；之后不再用于校验
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (SigningKey) TableName() string {
+	return "jwt_signing_keys"
+}
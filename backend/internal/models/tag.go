@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag 标签词表中的一个标签。项目标签统一指向这里的规范标签，
+// 大小写或写法不同的输入（如 react、ReactJS）通过 Slug 和同义词归并到同一个标签
+type Tag struct {
+	ID          int64     `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"size:50;not null"`                    // 规范写法，如 React
+	Slug        string    `json:"slug" gorm:"size:50;uniqueIndex;not null"`        // 小写的规范写法，用于查找
+	TagType     string    `json:"tag_type" gorm:"size:20;not null;default:'tech'"` // tech, domain, function, stage, hackathon
+	Description string    `json:"description" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Synonyms []TagSynonym `json:"synonyms,omitempty" gorm:"foreignKey:TagID"`
+}
+
+// TagSynonym 标签的其它写法，保存为小写
+type TagSynonym struct {
+	ID        int64     `json:"id" gorm:"primaryKey"`
+	TagID     int64     `json:"tag_id" gorm:"not null;index"`
+	Synonym   string    `json:"synonym" gorm:"size:50;uniqueIndex;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// TableName 指定表名
+func (TagSynonym) TableName() string {
+	return "tag_synonyms"
+}
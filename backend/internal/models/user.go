@@ -5,18 +5,27 @@ import (
 )
 
 type User struct {
-	ID             int64     `json:"id" gorm:"primaryKey"`
-	Username       string    `json:"username" gorm:"size:50;uniqueIndex;not null"`
-	Email          string    `json:"email" gorm:"size:100;uniqueIndex;not null"`
-	PasswordHash   string    `json:"-" gorm:"size:255;not null"`
-	AvatarURL      string    `json:"avatar_url" gorm:"size:500"`
-	Bio            string    `json:"bio" gorm:"type:text"`
-	TechStack      string    `json:"tech_stack" gorm:"type:text"`
-	IsCreator      bool      `json:"is_creator" gorm:"default:false"`
-	FollowerCount  int       `json:"follower_count" gorm:"default:0"`
-	FollowingCount int       `json:"following_count" gorm:"default:0"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID                int64      `json:"id" gorm:"primaryKey"`
+	Username          string     `json:"username" gorm:"size:50;uniqueIndex;not null"`
+	Email             string     `json:"email" gorm:"size:100;uniqueIndex;not null"`
+	PasswordHash      string     `json:"-" gorm:"size:255;not null"`
+	EmailVerifiedAt   *time.Time `json:"email_verified_at"`
+	AvatarURL         string     `json:"avatar_url" gorm:"size:500"`
+	Bio               string     `json:"bio" gorm:"type:text"`
+	TechStack         string     `json:"tech_stack" gorm:"type:text"`
+	IsCreator         bool       `json:"is_creator" gorm:"default:false"`
+	Role              string     `json:"role" gorm:"size:20;default:'user';index"` // user, creator, moderator, admin
+	FollowerCount     int        `json:"follower_count" gorm:"default:0"`
+	FollowingCount    int        `json:"following_count" gorm:"default:0"`
+	ProfileVisibility string     `json:"profile_visibility" gorm:"size:20;default:'public'"` // public, followers, private
+	ShowEmail         bool       `json:"show_email" gorm:"default:false"`                    // 是否在个人主页公开邮箱
+	Location          string     `json:"location" gorm:"size:100"`
+	Website           string     `json:"website" gorm:"size:500"`
+	GitHubHandle      string     `json:"github_handle" gorm:"size:39"`
+	OpenToWork        bool       `json:"open_to_work" gorm:"default:false"`  // 是否在主页展示求职、接受合作邀请
+	DeletionAt        *time.Time `json:"deletion_at,omitempty" gorm:"index"` // 申请注销后计划删除数据的时间，为空表示未申请
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
 
 	// 关联字段 - 暂时注释掉以避免循环引用问题
 	// Projects     []Project         `json:"projects,omitempty" gorm:"foreignKey:UserID"`
@@ -25,12 +34,29 @@ type User struct {
 	// Collections  []Collection      `json:"collections,omitempty" gorm:"foreignKey:UserID"`
 }
 
+// Author 项目、评论等内容中嵌入的作者信息，只包含公开字段，邮箱等资料只能通过个人主页按隐私设置获取
+type Author struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username"`
+	AvatarURL string `json:"avatar_url"`
+	IsCreator bool   `json:"is_creator"`
+}
+
+// TableName 与 User 共用 users 表
+func (Author) TableName() string {
+	return "users"
+}
+
 type UserPreferences struct {
-	ID            int64     `json:"id" gorm:"primaryKey"`
-	UserID        int64     `json:"user_id" gorm:"not null"`
-	PreferredTags string    `json:"preferred_tags" gorm:"type:text"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID                int64      `json:"id" gorm:"primaryKey"`
+	UserID            int64      `json:"user_id" gorm:"uniqueIndex;not null"`
+	PreferredTags     string     `json:"preferred_tags" gorm:"type:text"`    // 标签亲和度画像，JSON对象 {tag: weight}
+	DecayedAt         *time.Time `json:"decayed_at"`                         // 标签权重最近一次衰减的时间
+	FollowedTags      string     `json:"followed_tags" gorm:"type:text"`     // 用户显式关注的标签，逗号分隔
+	MutedTags         string     `json:"muted_tags" gorm:"type:text"`        // 用户屏蔽的标签，逗号分隔
+	PreferredStatuses string     `json:"preferred_statuses" gorm:"size:100"` // 偏好的项目阶段，逗号分隔
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
 
 	User User `json:"user" gorm:"foreignKey:UserID"`
 }
@@ -48,7 +74,123 @@ type UserFollow struct {
 	// UniqueConstraint struct{} `gorm:"uniqueIndex:idx_follower_following,unique"`
 }
 
+// UserBlock 拉黑关系：被拉黑的用户不能关注、评论拉黑者，双方互相看不到对方的项目和评论
+type UserBlock struct {
+	ID        int64     `json:"id" gorm:"primaryKey"`
+	UserID    int64     `json:"user_id" gorm:"not null;uniqueIndex:idx_user_blocked"`
+	BlockedID int64     `json:"blocked_id" gorm:"not null;uniqueIndex:idx_user_blocked;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserMute 静音关系：只对静音者隐藏对方的项目和评论，不影响对方的任何操作
+type UserMute struct {
+	ID        int64     `json:"id" gorm:"primaryKey"`
+	UserID    int64     `json:"user_id" gorm:"not null;uniqueIndex:idx_user_muted"`
+	MutedID   int64     `json:"muted_id" gorm:"not null;uniqueIndex:idx_user_muted"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UsernameRedirect 用户改名后保留的旧用户名，按旧用户名访问主页时跳转到新用户名，旧用户名不能再被其他用户注册
+type UsernameRedirect struct {
+	ID          int64     `json:"id" gorm:"primaryKey"`
+	OldUsername string    `json:"old_username" gorm:"size:50;uniqueIndex;not null"`
+	UserID      int64     `json:"user_id" gorm:"not null;index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// UserToken 一次性、限时的账户令牌，如密码重置、邮箱验证。只保存令牌的哈希
+type UserToken struct {
+	ID        int64      `json:"id" gorm:"primaryKey"`
+	UserID    int64      `json:"user_id" gorm:"not null;index"`
+	Purpose   string     `json:"purpose" gorm:"size:30;not null"` // password_reset, email_verification
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// UserIdentity 用户绑定的第三方登录身份
+type UserIdentity struct {
+	ID             int64     `json:"id" gorm:"primaryKey"`
+	UserID         int64     `json:"user_id" gorm:"not null;index"`
+	Provider       string    `json:"provider" gorm:"size:30;not null;uniqueIndex:idx_provider_user"`
+	ProviderUserID string    `json:"provider_user_id" gorm:"size:100;not null;uniqueIndex:idx_provider_user"`
+	Login          string    `json:"login" gorm:"size:100"`
+	Email          string    `json:"email" gorm:"size:100"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// UserSession 一次登录产生的会话，对应JWT中的 jti。撤销后该JWT立即失效
+type UserSession struct {
+	ID         int64      `json:"id" gorm:"primaryKey"`
+	UserID     int64      `json:"user_id" gorm:"not null;index"`
+	TokenID    string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	Device     string     `json:"device" gorm:"size:100"`
+	UserAgent  string     `json:"user_agent" gorm:"size:255"`
+	IP         string     `json:"ip" gorm:"size:45"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// UserMFA 用户的TOTP两步验证设置。密钥需要用于计算验证码，因此不能只保存哈希
+type UserMFA struct {
+	ID           int64      `json:"id" gorm:"primaryKey"`
+	UserID       int64      `json:"user_id" gorm:"uniqueIndex;not null"`
+	Secret       string     `json:"-" gorm:"size:64;not null"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"` // 为空表示已生成密钥但尚未确认，不生效
+	LastUsedStep int64      `json:"-"`            // 最近一次使用的时间步，防止验证码被重放
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// MFARecoveryCode 丢失认证器时使用的一次性恢复码，只保存哈希
+type MFARecoveryCode struct {
+	ID        int64      `json:"id" gorm:"primaryKey"`
+	UserID    int64      `json:"user_id" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"size:64;not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// TableName 指定表名
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// TableName 指定表名
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// TableName 指定表名
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// TableName 指定表名
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
+
 // TableName 指定表名
 func (UserFollow) TableName() string {
 	return "user_follows"
 }
+
+// TableName 指定表名
+func (UserBlock) TableName() string {
+	return "user_blocks"
+}
+
+// TableName 指定表名
+func (UserMute) TableName() string {
+	return "user_mutes"
+}
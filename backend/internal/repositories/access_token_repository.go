@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// accessTokenTouchInterval 最近使用时间的更新间隔，避免每个请求都写数据库
+const accessTokenTouchInterval = time.Minute
+
+type AccessTokenRepository struct{}
+
+func NewAccessTokenRepository() *AccessTokenRepository {
+	return &AccessTokenRepository{}
+}
+
+func (r *AccessTokenRepository) Create(token *models.PersonalAccessToken) error {
+	return database.DB.Create(token).Error
+}
+
+// GetByHash 按哈希查找令牌，不存在时返回nil
+func (r *AccessTokenRepository) GetByHash(tokenHash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	err := database.DB.Where("token_hash = ?", tokenHash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetActiveByUser 获取用户未撤销的令牌，按创建时间倒序
+func (r *AccessTokenRepository) GetActiveByUser(userID int64) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	err := database.DB.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+func (r *AccessTokenRepository) CountActiveByUser(userID int64) (int64, error) {
+	var count int64
+	err := database.DB.Model(&models.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// Revoke 撤销用户的一个令牌，返回是否找到了未撤销的令牌
+func (r *AccessTokenRepository) Revoke(userID, tokenID int64) (bool, error) {
+	result := database.DB.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeAll 撤销用户的所有令牌
+func (r *AccessTokenRepository) RevokeAll(userID int64) error {
+	return database.DB.Model(&models.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// Touch 记录令牌的最近使用时间和IP，距上次记录不足一分钟时跳过
+func (r *AccessTokenRepository) Touch(tokenID int64, ip string) error {
+	now := time.Now()
+	return database.DB.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", tokenID, now.Add(-accessTokenTouchInterval)).
+		Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": ip}).Error
+}
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"devswipe-backend/internal/models"
+	"devswipe-backend/pkg/database"
+)
+
+type AuditRepository struct{}
+
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{}
+}
+
+func (r *AuditRepository) Create(log *models.AuditLog) error {
+	return database.DB.Create(log).Error
+}
+
+// GetByUser 获取某个用户的审计日志，按时间倒序
+func (r *AuditRepository) GetByUser(userID int64, limit, offset int) ([]models.AuditLog, error) {
+	var logs []models.AuditLog
+	err := database.DB.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&logs).Error
+	return logs, err
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"devswipe-backend/internal/models"
+	"devswipe-backend/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+type BlockRepository struct{}
+
+func NewBlockRepository() *BlockRepository {
+	return &BlockRepository{}
+}
+
+// Block 拉黑用户，同时解除双方之间的关注关系并更新关注数。已拉黑时不报错
+func (r *BlockRepository) Block(userID, blockedID int64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		block := models.UserBlock{UserID: userID, BlockedID: blockedID}
+		if err := tx.Where(&block).FirstOrCreate(&block).Error; err != nil {
+			return err
+		}
+
+		for _, pair := range [][2]int64{{userID, blockedID}, {blockedID, userID}} {
+			followerID, followingID := pair[0], pair[1]
+			result := tx.Where("follower_id = ? AND following_id = ?", followerID, followingID).Delete(&models.UserFollow{})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+
+			if err := tx.Model(&models.User{}).Where("id = ?", followingID).
+				Update("follower_count", gorm.Expr("GREATEST(follower_count - ?, 0)", result.RowsAffected)).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.User{}).Where("id = ?", followerID).
+				Update("following_count", gorm.Expr("GREATEST(following_count - ?, 0)", result.RowsAffected)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Unblock 取消拉黑，返回之前是否拉黑过
+func (r *BlockRepository) Unblock(userID, blockedID int64) (bool, error) {
+	result := database.DB.Where("user_id = ? AND blocked_id = ?", userID, blockedID).Delete(&models.UserBlock{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// IsBlockedEither 两个用户之间是否有任意一方拉黑了另一方
+func (r *BlockRepository) IsBlockedEither(userID, otherID int64) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.UserBlock{}).
+		Where("(user_id = ? AND blocked_id = ?) OR (user_id = ? AND blocked_id = ?)", userID, otherID, otherID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// IsBlocked userID 是否拉黑了 blockedID
+func (r *BlockRepository) IsBlocked(userID, blockedID int64) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.UserBlock{}).
+		Where("user_id = ? AND blocked_id = ?", userID, blockedID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// IsMuted userID 是否静音了 mutedID
+func (r *BlockRepository) IsMuted(userID, mutedID int64) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.UserMute{}).
+		Where("user_id = ? AND muted_id = ?", userID, mutedID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Mute 静音用户，已静音时不报错
+func (r *BlockRepository) Mute(userID, mutedID int64) error {
+	mute := models.UserMute{UserID: userID, MutedID: mutedID}
+	return database.DB.Where(&mute).FirstOrCreate(&mute).Error
+}
+
+// Unmute 取消静音，返回之前是否静音过
+func (r *BlockRepository) Unmute(userID, mutedID int64) (bool, error) {
+	result := database.DB.Where("user_id = ? AND muted_id = ?", userID, mutedID).Delete(&models.UserMute{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetHiddenUserIDs 获取内容需要对该用户隐藏的用户：自己拉黑或静音的用户，以及拉黑了自己的用户
+func (r *BlockRepository) GetHiddenUserIDs(userID int64) ([]int64, error) {
+	var blocked, blockers, muted []int64
+	if err := database.DB.Model(&models.UserBlock{}).Where("user_id = ?", userID).Pluck("blocked_id", &blocked).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Model(&models.UserBlock{}).Where("blocked_id = ?", userID).Pluck("user_id", &blockers).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Model(&models.UserMute{}).Where("user_id = ?", userID).Pluck("muted_id", &muted).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(blocked)+len(blockers)+len(muted))
+	ids = append(ids, blocked...)
+	ids = append(ids, blockers...)
+	return append(ids, muted...), nil
+}
+
+// GetBlockedUsers 获取用户拉黑的用户列表
+func (r *BlockRepository) GetBlockedUsers(userID int64, limit, offset int) ([]models.User, error) {
+	var users []models.User
+	err := database.DB.Table("users").
+		Joins("JOIN user_blocks ON users.id = user_blocks.blocked_id").
+		Where("user_blocks.user_id = ?", userID).
+		Order("user_blocks.created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&users).Error
+	return users, err
+}
+
+// GetMutedUsers 获取用户静音的用户列表
+func (r *BlockRepository) GetMutedUsers(userID int64, limit, offset int) ([]models.User, error) {
+	var users []models.User
+	err := database.DB.Table("users").
+		Joins("JOIN user_mutes ON users.id = user_mutes.muted_id").
+		Where("user_mutes.user_id = ?", userID).
+		Order("user_mutes.created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&users).Error
+	return users, err
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"devswipe-backend/internal/models"
+	"devswipe-backend/pkg/database"
+)
+
+type ExperimentRepository struct{}
+
+func NewExperimentRepository() *ExperimentRepository {
+	return &ExperimentRepository{}
+}
+
+// VariantExposureStats 变体曝光统计
+type VariantExposureStats struct {
+	Variant   string
+	Exposures int64
+	Units     int64
+}
+
+// VariantInteractionStats 变体交互统计，停留时长只统计大于0的样本
+type VariantInteractionStats struct {
+	Variant      string
+	Swipes       int64
+	Likes        int64
+	DwellSamples int64
+	DwellSum     float64
+	DwellSqSum   float64
+}
+
+func (r *ExperimentRepository) CreateExposure(exposure *models.ExperimentExposure) error {
+	return database.DB.Create(exposure).Error
+}
+
+// GetLatestExposure 获取实验中最近一次曝光：sessionID 不为空时按会话查找，否则按用户查找。不存在时返回 nil
+func (r *ExperimentRepository) GetLatestExposure(experiment string, userID int64, sessionID string) (*models.ExperimentExposure, error) {
+	query := database.DB.Where("experiment = ?", experiment)
+	if sessionID != "" {
+		query = query.Where("session_id = ?", sessionID)
+	} else {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var exposures []models.ExperimentExposure
+	err := query.Order("id DESC").
+		Limit(1).
+		Find(&exposures).Error
+	if err != nil || len(exposures) == 0 {
+		return nil, err
+	}
+	return &exposures[0], nil
+}
+
+func (r *ExperimentRepository) GetExposureStats(experiment string) ([]VariantExposureStats, error) {
+	var stats []VariantExposureStats
+	err := database.DB.Model(&models.ExperimentExposure{}).
+		Select("variant, COUNT(*) AS exposures, COUNT(DISTINCT unit_id) AS units").
+		Where("experiment = ?", experiment).
+		Group("variant").
+		Scan(&stats).Error
+	return stats, err
+}
+
+func (r *ExperimentRepository) GetInteractionStats(experiment string) ([]VariantInteractionStats, error) {
+	var stats []VariantInteractionStats
+	err := database.DB.Model(&models.UserInteraction{}).
+		Select(`variant,
+			SUM(CASE WHEN interaction_type IN ('like', 'super_like', 'dislike', 'skip') THEN 1 ELSE 0 END) AS swipes,
+			SUM(CASE WHEN interaction_type IN ('like', 'super_like') THEN 1 ELSE 0 END) AS likes,
+			SUM(CASE WHEN view_duration > 0 THEN 1 ELSE 0 END) AS dwell_samples,
+			COALESCE(SUM(CASE WHEN view_duration > 0 THEN view_duration ELSE 0 END), 0) AS dwell_sum,
+			COALESCE(SUM(CASE WHEN view_duration > 0 THEN view_duration * view_duration ELSE 0 END), 0) AS dwell_sq_sum`).
+		Where("experiment = ?", experiment).
+		Group("variant").
+		Scan(&stats).Error
+	return stats, err
+}
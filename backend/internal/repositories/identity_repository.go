@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"errors"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+type IdentityRepository struct{}
+
+func NewIdentityRepository() *IdentityRepository {
+	return &IdentityRepository{}
+}
+
+func (r *IdentityRepository) Create(identity *models.UserIdentity) error {
+	return database.DB.Create(identity).Error
+}
+
+// GetByProviderUserID 按第三方平台的用户ID查找绑定关系，不存在时返回nil
+func (r *IdentityRepository) GetByProviderUserID(provider, providerUserID string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := database.DB.Where("provider = ? AND provider_user_id = ?", provider, providerUserID).First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *IdentityRepository) GetByUserID(userID int64) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&identities).Error
+	return identities, err
+}
+
+// Delete 解绑用户在某个平台的身份，返回是否有记录被删除
+func (r *IdentityRepository) Delete(userID int64, provider string) (bool, error) {
+	result := database.DB.Where("user_id = ? AND provider = ?", userID, provider).Delete(&models.UserIdentity{})
+	return result.RowsAffected > 0, result.Error
+}
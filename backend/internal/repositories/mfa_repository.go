@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+type MFARepository struct{}
+
+func NewMFARepository() *MFARepository {
+	return &MFARepository{}
+}
+
+// GetByUserID 获取用户的两步验证设置，不存在时返回nil
+func (r *MFARepository) GetByUserID(userID int64) (*models.UserMFA, error) {
+	var mfa models.UserMFA
+	err := database.DB.Where("user_id = ?", userID).First(&mfa).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+func (r *MFARepository) Save(mfa *models.UserMFA) error {
+	return database.DB.Save(mfa).Error
+}
+
+// UseStep 记录已使用的时间步，同一时间步或更早的验证码再次使用时返回false
+func (r *MFARepository) UseStep(userID, step int64) (bool, error) {
+	result := database.DB.Model(&models.UserMFA{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Delete 删除用户的两步验证设置和恢复码
+func (r *MFARepository) Delete(userID int64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserMFA{}).Error
+	})
+}
+
+// ReplaceRecoveryCodes 用新的恢复码替换用户所有的恢复码
+func (r *MFARepository) ReplaceRecoveryCodes(userID int64, codeHashes []string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]models.MFARecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, models.MFARecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// ConsumeRecoveryCode 原子地使用一个未使用的恢复码，返回是否成功
+func (r *MFARepository) ConsumeRecoveryCode(userID int64, codeHash string) (bool, error) {
+	result := database.DB.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Limit(1).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *MFARepository) CountUnusedRecoveryCodes(userID int64) (int64, error) {
+	var count int64
+	err := database.DB.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
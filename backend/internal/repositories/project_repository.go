@@ -1,10 +1,13 @@
 package repositories
 
 import (
+	"time"
+
 	"devswipe-backend/internal/models"
 	"devswipe-backend/pkg/database"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProjectRepository struct{}
@@ -26,10 +29,70 @@ func (r *ProjectRepository) GetByID(id int64) (*models.Project, error) {
 	return &project, nil
 }
 
-func (r *ProjectRepository) GetByUserID(userID int64, limit, offset int) ([]models.Project, error) {
+// GetByIDs 批量获取项目，结果按传入ID的顺序排列
+func (r *ProjectRepository) GetByIDs(ids []int64) ([]models.Project, error) {
+	var projects []models.Project
+	if len(ids) == 0 {
+		return projects, nil
+	}
+
+	err := database.DB.Preload("User").Preload("Tags").
+		Where("id IN ?", ids).
+		Find(&projects).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]models.Project, len(projects))
+	for _, project := range projects {
+		byID[project.ID] = project
+	}
+
+	ordered := make([]models.Project, 0, len(projects))
+	for _, id := range ids {
+		if project, ok := byID[id]; ok {
+			ordered = append(ordered, project)
+		}
+	}
+	return ordered, nil
+}
+
+// CreatorStats 创作者在个人主页展示的汇总数据
+type CreatorStats struct {
+	ProjectCount    int64 `json:"project_count"`
+	TotalViews      int64 `json:"total_views"`
+	TotalLikes      int64 `json:"total_likes"`
+	TotalSuperLikes int64 `json:"total_super_likes"`
+	TotalComments   int64 `json:"total_comments"`
+}
+
+// GetCreatorStats 汇总用户发布的项目数据，includeHidden 为 false 时只统计公开项目
+func (r *ProjectRepository) GetCreatorStats(userID int64, includeHidden bool) (*CreatorStats, error) {
+	query := database.DB.Model(&models.Project{}).
+		Select("COUNT(*) AS project_count, "+
+			"COALESCE(SUM(view_count), 0) AS total_views, "+
+			"COALESCE(SUM(like_count), 0) AS total_likes, "+
+			"COALESCE(SUM(super_like_count), 0) AS total_super_likes, "+
+			"COALESCE(SUM(comment_count), 0) AS total_comments").
+		Where("user_id = ?", userID)
+	if !includeHidden {
+		query = query.Where("is_public = ?", true)
+	}
+
+	var stats CreatorStats
+	err := query.Scan(&stats).Error
+	return &stats, err
+}
+
+// GetByUserID 获取用户发布的项目，includeHidden 为 false 时只返回公开项目
+func (r *ProjectRepository) GetByUserID(userID int64, includeHidden bool, limit, offset int) ([]models.Project, error) {
+	query := database.DB.Preload("Tags").Where("user_id = ?", userID)
+	if !includeHidden {
+		query = query.Where("is_public = ?", true)
+	}
+
 	var projects []models.Project
-	err := database.DB.Preload("Tags").
-		Where("user_id = ?", userID).
+	err := query.
 		Order("created_at DESC").
 		Limit(limit).Offset(offset).
 		Find(&projects).Error
@@ -62,16 +125,15 @@ func (r *ProjectRepository) GetRecommendedProjects(userID int64, limit int) ([]m
 	return projects, err
 }
 
-func (r *ProjectRepository) GetProjectsByTags(tags []string, limit, offset int) ([]models.Project, error) {
+// GetProjectsByTags 获取带有任一指定标签的公开项目，按发布时间倒序
+func (r *ProjectRepository) GetProjectsByTags(tagIDs []int64, limit, offset int) ([]models.Project, error) {
 	var projects []models.Project
 
 	query := database.DB.Preload("User").Preload("Tags").
 		Where("is_public = ?", true)
 
-	if len(tags) > 0 {
-		query = query.Joins("JOIN project_tags ON projects.id = project_tags.project_id").
-			Where("project_tags.tag_name IN ?", tags).
-			Group("projects.id")
+	if len(tagIDs) > 0 {
+		query = query.Where("id IN (SELECT project_id FROM project_tags WHERE tag_id IN ?)", tagIDs)
 	}
 
 	err := query.Order("created_at DESC").
@@ -80,13 +142,29 @@ func (r *ProjectRepository) GetProjectsByTags(tags []string, limit, offset int)
 	return projects, err
 }
 
-func (r *ProjectRepository) SearchProjects(keyword string, limit, offset int) ([]models.Project, error) {
+// GetTopProjectsByTag 获取该标签下累计互动最多的公开项目，权重与热榜一致但不随时间衰减
+func (r *ProjectRepository) GetTopProjectsByTag(tagID int64, limit int) ([]models.Project, error) {
 	var projects []models.Project
-
 	err := database.DB.Preload("User").Preload("Tags").
+		Where("is_public = ? AND id IN (SELECT project_id FROM project_tags WHERE tag_id = ?)", true, tagID).
+		Order("like_count + 3 * super_like_count + 2 * comment_count + 0.05 * view_count DESC, created_at DESC").
+		Limit(limit).
+		Find(&projects).Error
+	return projects, err
+}
+
+// SearchProjects 按标题和描述搜索公开项目，excludeUserIDs 中的作者发布的项目不返回
+func (r *ProjectRepository) SearchProjects(keyword string, excludeUserIDs []int64, limit, offset int) ([]models.Project, error) {
+	var projects []models.Project
+
+	query := database.DB.Preload("User").Preload("Tags").
 		Where("is_public = ? AND (title LIKE ? OR description LIKE ?)",
-			true, "%"+keyword+"%", "%"+keyword+"%").
-		Order("created_at DESC").
+			true, "%"+keyword+"%", "%"+keyword+"%")
+	if len(excludeUserIDs) > 0 {
+		query = query.Where("user_id NOT IN ?", excludeUserIDs)
+	}
+
+	err := query.Order("created_at DESC").
 		Limit(limit).Offset(offset).
 		Find(&projects).Error
 	return projects, err
@@ -133,3 +211,212 @@ func (r *ProjectRepository) GetProjectStats(projectID int64) (*models.ProjectSta
 
 	return stats, nil
 }
+
+// IncrementImpressions 记录项目在浏览流中的一次曝光
+func (r *ProjectRepository) IncrementImpressions(projectIDs []int64) error {
+	if len(projectIDs) == 0 {
+		return nil
+	}
+
+	stats := make([]models.ProjectBanditStats, 0, len(projectIDs))
+	for _, id := range projectIDs {
+		stats = append(stats, models.ProjectBanditStats{ProjectID: id, Impressions: 1})
+	}
+
+	return database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"impressions": gorm.Expr("impressions + 1")}),
+	}).Create(&stats).Error
+}
+
+// RecordBanditOutcome 记录一次探索反馈（成功=喜欢，失败=不喜欢/跳过）
+func (r *ProjectRepository) RecordBanditOutcome(projectID int64, success bool) error {
+	stats := models.ProjectBanditStats{ProjectID: projectID}
+	field := "failures"
+	if success {
+		stats.Successes = 1
+		field = "successes"
+	} else {
+		stats.Failures = 1
+	}
+
+	return database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{field: gorm.Expr(field + " + 1")}),
+	}).Create(&stats).Error
+}
+
+// GetBanditStats 批量获取项目探索统计
+func (r *ProjectRepository) GetBanditStats(projectIDs []int64) (map[int64]models.ProjectBanditStats, error) {
+	result := make(map[int64]models.ProjectBanditStats)
+	if len(projectIDs) == 0 {
+		return result, nil
+	}
+
+	var stats []models.ProjectBanditStats
+	if err := database.DB.Where("project_id IN ?", projectIDs).Find(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	for _, stat := range stats {
+		result[stat.ProjectID] = stat
+	}
+	return result, nil
+}
+
+// ProjectActivity 项目在时间窗口内的互动统计
+type ProjectActivity struct {
+	ProjectID  int64
+	Likes      int
+	SuperLikes int
+	Comments   int
+}
+
+// GetActivitySince 统计某个时间点之后各项目的喜欢、超级喜欢和评论数
+func (r *ProjectRepository) GetActivitySince(since time.Time) (map[int64]*ProjectActivity, error) {
+	var interactions []struct {
+		ProjectID  int64
+		Likes      int
+		SuperLikes int
+	}
+	err := database.DB.Model(&models.UserInteraction{}).
+		Select(`project_id,
+			SUM(CASE WHEN interaction_type = 'like' THEN 1 ELSE 0 END) AS likes,
+			SUM(CASE WHEN interaction_type = 'super_like' THEN 1 ELSE 0 END) AS super_likes`).
+		Where("created_at >= ? AND interaction_type IN ?", since, []string{"like", "super_like"}).
+		Group("project_id").
+		Scan(&interactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []struct {
+		ProjectID int64
+		Comments  int
+	}
+	err = database.DB.Model(&models.Comment{}).
+		Select("project_id, COUNT(*) AS comments").
+		Where("created_at >= ?", since).
+		Group("project_id").
+		Scan(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]*ProjectActivity)
+	for _, row := range interactions {
+		result[row.ProjectID] = &ProjectActivity{ProjectID: row.ProjectID, Likes: row.Likes, SuperLikes: row.SuperLikes}
+	}
+	for _, row := range comments {
+		if _, ok := result[row.ProjectID]; !ok {
+			result[row.ProjectID] = &ProjectActivity{ProjectID: row.ProjectID}
+		}
+		result[row.ProjectID].Comments = row.Comments
+	}
+	return result, nil
+}
+
+// GetTrendingCandidates 获取时间窗口内发布或有互动的公开项目
+func (r *ProjectRepository) GetTrendingCandidates(since time.Time, activeIDs []int64) ([]models.Project, error) {
+	var projects []models.Project
+
+	query := database.DB.Preload("Tags").Where("is_public = ?", true)
+	if len(activeIDs) > 0 {
+		query = query.Where("created_at >= ? OR id IN ?", since, activeIDs)
+	} else {
+		query = query.Where("created_at >= ?", since)
+	}
+
+	err := query.Find(&projects).Error
+	return projects, err
+}
+
+// 关注动态流中的条目类型，同一时间戳下按类型倒序排列
+const (
+	FeedItemProject = "project"
+	FeedItemUpdate  = "update"
+)
+
+// FeedCursor 关注动态流的分页游标，指向上一页最后一个条目
+type FeedCursor struct {
+	CreatedAt time.Time
+	Kind      string
+	ID        int64
+}
+
+// before 生成早于游标的查询条件，排序为 created_at DESC, kind DESC, id DESC
+func (c *FeedCursor) before(query *gorm.DB, kind, table string) *gorm.DB {
+	if c == nil {
+		return query
+	}
+	switch {
+	case kind < c.Kind:
+		return query.Where(table+".created_at <= ?", c.CreatedAt)
+	case kind > c.Kind:
+		return query.Where(table+".created_at < ?", c.CreatedAt)
+	default:
+		return query.Where(table+".created_at < ? OR ("+table+".created_at = ? AND "+table+".id < ?)", c.CreatedAt, c.CreatedAt, c.ID)
+	}
+}
+
+// followingSubQuery 读取时扇出：通过子查询取关注的作者，不需要为每个关注者写入收件箱。静音的作者不出现在动态流中
+func followingSubQuery(followerID int64) *gorm.DB {
+	return database.DB.Model(&models.UserFollow{}).Select("following_id").
+		Where("follower_id = ?", followerID).
+		Where("following_id NOT IN (?)", database.DB.Model(&models.UserMute{}).Select("muted_id").Where("user_id = ?", followerID))
+}
+
+// GetFollowingProjects 获取关注的作者发布的公开项目，按发布时间倒序
+func (r *ProjectRepository) GetFollowingProjects(followerID int64, cursor *FeedCursor, limit int) ([]models.Project, error) {
+	var projects []models.Project
+
+	query := database.DB.Preload("User").Preload("Tags").
+		Where("projects.is_public = ? AND projects.user_id IN (?)", true, followingSubQuery(followerID))
+	err := cursor.before(query, FeedItemProject, "projects").
+		Order("projects.created_at DESC, projects.id DESC").
+		Limit(limit).
+		Find(&projects).Error
+	return projects, err
+}
+
+// GetFollowingUpdates 获取关注的作者对公开项目的更新，按时间倒序
+func (r *ProjectRepository) GetFollowingUpdates(followerID int64, cursor *FeedCursor, limit int) ([]models.ProjectUpdate, error) {
+	var updates []models.ProjectUpdate
+
+	query := database.DB.Preload("Project").Preload("Project.User").Preload("Project.Tags").
+		Joins("JOIN projects ON projects.id = project_updates.project_id").
+		Where("projects.is_public = ? AND project_updates.user_id IN (?)", true, followingSubQuery(followerID))
+	err := cursor.before(query, FeedItemUpdate, "project_updates").
+		Order("project_updates.created_at DESC, project_updates.id DESC").
+		Limit(limit).
+		Find(&updates).Error
+	return updates, err
+}
+
+// CreateUpdate 记录一次项目更新
+func (r *ProjectRepository) CreateUpdate(update *models.ProjectUpdate) error {
+	return database.DB.Create(update).Error
+}
+
+// AdminSearch 管理后台搜索项目，包括未公开的项目，同时返回总数
+func (r *ProjectRepository) AdminSearch(keyword string, userID int64, limit, offset int) ([]models.Project, int64, error) {
+	query := database.DB.Model(&models.Project{})
+	if keyword != "" {
+		query = query.Where("title LIKE ? OR description LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var projects []models.Project
+	err := query.Preload("User").Preload("Tags").
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&projects).Error
+	return projects, total, err
+}
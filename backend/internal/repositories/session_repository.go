@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// sessionTouchInterval 最近活跃时间的更新间隔，避免每个请求都写数据库
+const sessionTouchInterval = time.Minute
+
+type SessionRepository struct{}
+
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{}
+}
+
+func (r *SessionRepository) Create(session *models.UserSession) error {
+	return database.DB.Create(session).Error
+}
+
+// GetByTokenID 按 jti 查找会话，不存在时返回nil
+func (r *SessionRepository) GetByTokenID(tokenID string) (*models.UserSession, error) {
+	var session models.UserSession
+	err := database.DB.Where("token_id = ?", tokenID).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetActiveByUser 获取用户未撤销且未过期的会话，按最近活跃时间倒序
+func (r *SessionRepository) GetActiveByUser(userID int64) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := database.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Revoke 撤销用户的一个会话，返回被撤销会话的 jti，会话不存在或已撤销时返回空字符串
+func (r *SessionRepository) Revoke(userID, sessionID int64) (string, error) {
+	var session models.UserSession
+	err := database.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	result := database.DB.Model(&models.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", session.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil || result.RowsAffected == 0 {
+		return "", result.Error
+	}
+	return session.TokenID, nil
+}
+
+// RevokeAll 撤销用户的所有会话，exceptTokenID 不为空时保留该会话，返回被撤销会话的 jti
+func (r *SessionRepository) RevokeAll(userID int64, exceptTokenID string) ([]string, error) {
+	var tokenIDs []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.UserSession{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+		if exceptTokenID != "" {
+			query = query.Where("token_id <> ?", exceptTokenID)
+		}
+		if err := query.Pluck("token_id", &tokenIDs).Error; err != nil {
+			return err
+		}
+		if len(tokenIDs) == 0 {
+			return nil
+		}
+		return tx.Model(&models.UserSession{}).
+			Where("token_id IN ?", tokenIDs).
+			Update("revoked_at", time.Now()).Error
+	})
+	return tokenIDs, err
+}
+
+// Touch 记录会话的最近活跃时间和IP，距上次记录不足一分钟时跳过
+func (r *SessionRepository) Touch(sessionID int64, ip string) error {
+	now := time.Now()
+	return database.DB.Model(&models.UserSession{}).
+		Where("id = ? AND last_seen_at < ?", sessionID, now.Add(-sessionTouchInterval)).
+		Updates(map[string]interface{}{"last_seen_at": now, "ip": ip}).Error
+}
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"time"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/pkg/database"
+)
+
+type SigningKeyRepository struct{}
+
+func NewSigningKeyRepository() *SigningKeyRepository {
+	return &SigningKeyRepository{}
+}
+
+func (r *SigningKeyRepository) Create(key *models.SigningKey) error {
+	return database.DB.Create(key).Error
+}
+
+// GetUsable 获取仍可用于校验的密钥，按生效时间倒序
+func (r *SigningKeyRepository) GetUsable(now time.Time) ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	err := database.DB.Where("expires_at IS NULL OR expires_at > ?", now).
+		Order("activates_at DESC, id DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+// ExpireOthers 新密钥生效后，为其它尚未设置过期时间的密钥设置过期时间
+func (r *SigningKeyRepository) ExpireOthers(keepID int64, expiresAt time.Time) error {
+	return database.DB.Model(&models.SigningKey{}).
+		Where("id <> ? AND expires_at IS NULL", keepID).
+		Update("expires_at", expiresAt).Error
+}
@@ -0,0 +1,294 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+type TagRepository struct {
+	tx *gorm.DB
+}
+
+func NewTagRepository() *TagRepository {
+	return &TagRepository{}
+}
+
+// WithTx 返回在事务 tx 中执行的标签仓库，用于和项目一起创建标签
+func (r *TagRepository) WithTx(tx *gorm.DB) *TagRepository {
+	return &TagRepository{tx: tx}
+}
+
+func (r *TagRepository) conn() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return database.DB
+}
+
+// TagCount 标签及其公开项目数。相关标签中 ProjectCount 为同时带有两个标签的公开项目数
+type TagCount struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	TagType      string `json:"tag_type"`
+	ProjectCount int64  `json:"project_count"`
+}
+
+// UnlinkedTag 尚未关联到规范标签的历史项目标签
+type UnlinkedTag struct {
+	TagName string
+	TagType string
+}
+
+func (r *TagRepository) Create(tag *models.Tag) error {
+	return r.conn().Create(tag).Error
+}
+
+func (r *TagRepository) GetByID(id int64) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.conn().Preload("Synonyms").First(&tag, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// FindByKey 按小写写法查找标签，先匹配同义词再匹配 Slug，不存在时返回 nil
+func (r *TagRepository) FindByKey(key string) (*models.Tag, error) {
+	var synonym models.TagSynonym
+	err := r.conn().Where("synonym = ?", key).First(&synonym).Error
+	if err == nil {
+		return r.GetByID(synonym.TagID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var tag models.Tag
+	err = r.conn().Where("slug = ?", key).First(&tag).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// FindByKeys 批量按小写写法查找标签，返回以写法为键的标签，不存在的写法不出现在结果中。
+// 同义词优先于 Slug，与 FindByKey 一致
+func (r *TagRepository) FindByKeys(keys []string) (map[string]*models.Tag, error) {
+	found := make(map[string]*models.Tag, len(keys))
+	if len(keys) == 0 {
+		return found, nil
+	}
+
+	var synonyms []models.TagSynonym
+	if err := r.conn().Where("synonym IN ?", keys).Find(&synonyms).Error; err != nil {
+		return nil, err
+	}
+	tagIDs := make([]int64, 0, len(synonyms))
+	for _, synonym := range synonyms {
+		tagIDs = append(tagIDs, synonym.TagID)
+	}
+
+	query := r.conn().Where("slug IN ?", keys)
+	if len(tagIDs) > 0 {
+		query = query.Or("id IN ?", tagIDs)
+	}
+	var tags []models.Tag
+	if err := query.Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*models.Tag, len(tags))
+	for i := range tags {
+		byID[tags[i].ID] = &tags[i]
+		found[tags[i].Slug] = &tags[i]
+	}
+	for _, synonym := range synonyms {
+		if tag := byID[synonym.TagID]; tag != nil {
+			found[synonym.Synonym] = tag
+		}
+	}
+	return found, nil
+}
+
+// KeyInUse 小写写法是否已被其他标签用作 Slug 或同义词
+func (r *TagRepository) KeyInUse(key string, exceptTagID int64) (bool, error) {
+	var count int64
+	if err := r.conn().Model(&models.Tag{}).Where("slug = ? AND id <> ?", key, exceptTagID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	err := r.conn().Model(&models.TagSynonym{}).Where("synonym = ? AND tag_id <> ?", key, exceptTagID).Count(&count).Error
+	return count > 0, err
+}
+
+// List 按关键字和类型查询标签，供版主维护词表
+func (r *TagRepository) List(keyword, tagType string, limit, offset int) ([]models.Tag, int64, error) {
+	query := r.conn().Model(&models.Tag{})
+	if keyword != "" {
+		like := "%" + keyword + "%"
+		query = query.Where("name LIKE ? OR slug LIKE ?", like, like)
+	}
+	if tagType != "" {
+		query = query.Where("tag_type = ?", tagType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var tags []models.Tag
+	err := query.Preload("Synonyms").Order("slug").Limit(limit).Offset(offset).Find(&tags).Error
+	return tags, total, err
+}
+
+// Update 保存标签，并同步项目标签上的标签名和类型
+func (r *TagRepository) Update(tag *models.Tag) error {
+	return r.conn().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(tag).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ProjectTag{}).Where("tag_id = ?", tag.ID).
+			Updates(map[string]interface{}{"tag_name": tag.Name, "tag_type": tag.TagType}).Error
+	})
+}
+
+func (r *TagRepository) AddSynonym(tagID int64, synonym string) error {
+	return r.conn().Create(&models.TagSynonym{TagID: tagID, Synonym: synonym}).Error
+}
+
+// RemoveSynonym 删除同义词，返回之前是否存在
+func (r *TagRepository) RemoveSynonym(tagID int64, synonym string) (bool, error) {
+	result := r.conn().Where("tag_id = ? AND synonym = ?", tagID, synonym).Delete(&models.TagSynonym{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Merge 将 source 合并到 target：项目标签改为指向 target（已有 target 的项目直接删除重复标签），
+// source 的 Slug 和同义词都成为 target 的同义词，最后删除 source
+func (r *TagRepository) Merge(source, target *models.Tag) error {
+	return r.conn().Transaction(func(tx *gorm.DB) error {
+		if err := relinkProjectTags(tx, target, "tag_id = ?", source.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.TagSynonym{}).Where("tag_id = ?", source.ID).Update("tag_id", target.ID).Error; err != nil {
+			return err
+		}
+		if source.Slug != target.Slug {
+			if err := tx.Create(&models.TagSynonym{TagID: target.ID, Synonym: source.Slug}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&models.Tag{}, source.ID).Error
+	})
+}
+
+// GetUnlinkedTags 获取还没有关联规范标签的历史项目标签（去重）
+func (r *TagRepository) GetUnlinkedTags() ([]UnlinkedTag, error) {
+	var tags []UnlinkedTag
+	err := r.conn().Model(&models.ProjectTag{}).
+		Select("tag_name, MIN(tag_type) AS tag_type").
+		Where("tag_id = 0 OR tag_id IS NULL").
+		Group("tag_name").
+		Scan(&tags).Error
+	return tags, err
+}
+
+// LinkProjectTags 将指定名称的历史项目标签关联到规范标签
+func (r *TagRepository) LinkProjectTags(tagName string, tag *models.Tag) error {
+	return r.conn().Transaction(func(tx *gorm.DB) error {
+		return relinkProjectTags(tx, tag, "(tag_id = 0 OR tag_id IS NULL) AND tag_name = ?", tagName)
+	})
+}
+
+// relinkProjectTags 把满足条件的项目标签改为指向 tag，项目已有该标签时删除重复的一条
+func relinkProjectTags(tx *gorm.DB, tag *models.Tag, query string, args ...interface{}) error {
+	var linked []int64
+	if err := tx.Model(&models.ProjectTag{}).Where("tag_id = ?", tag.ID).Pluck("project_id", &linked).Error; err != nil {
+		return err
+	}
+	if len(linked) > 0 {
+		if err := tx.Where(query, args...).Where("project_id IN ?", linked).Delete(&models.ProjectTag{}).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&models.ProjectTag{}).Where(query, args...).
+		Updates(map[string]interface{}{"tag_id": tag.ID, "tag_name": tag.Name, "tag_type": tag.TagType}).Error
+}
+
+// SearchByPrefix 按规范写法或同义词的前缀查找标签，按公开项目数排序
+func (r *TagRepository) SearchByPrefix(prefix string, limit int) ([]TagCount, error) {
+	like := escapeLike(prefix) + "%"
+	var tags []TagCount
+	err := tagCountQuery().
+		Where("tags.slug LIKE ? OR tags.id IN (SELECT tag_id FROM tag_synonyms WHERE synonym LIKE ?)", like, like).
+		Order("project_count DESC, tags.slug").
+		Limit(limit).
+		Scan(&tags).Error
+	return tags, err
+}
+
+// GetPopular 获取公开项目数最多的标签，tagType 为空时不限类型
+func (r *TagRepository) GetPopular(tagType string, limit int) ([]TagCount, error) {
+	query := tagCountQuery().Having("COUNT(projects.id) > 0")
+	if tagType != "" {
+		query = query.Where("tags.tag_type = ?", tagType)
+	}
+
+	var tags []TagCount
+	err := query.Order("project_count DESC, tags.slug").Limit(limit).Scan(&tags).Error
+	return tags, err
+}
+
+// CountProjects 统计带有该标签的公开项目数
+func (r *TagRepository) CountProjects(tagID int64) (int64, error) {
+	var count int64
+	err := r.conn().Model(&models.ProjectTag{}).
+		Joins("JOIN projects ON projects.id = project_tags.project_id AND projects.is_public = ?", true).
+		Where("project_tags.tag_id = ?", tagID).
+		Count(&count).Error
+	return count, err
+}
+
+// GetRelated 获取与该标签同时出现在公开项目上次数最多的标签
+func (r *TagRepository) GetRelated(tagID int64, limit int) ([]TagCount, error) {
+	var tags []TagCount
+	err := r.conn().Table("project_tags AS base").
+		Select("tags.id, tags.name, tags.slug, tags.tag_type, COUNT(DISTINCT base.project_id) AS project_count").
+		Joins("JOIN project_tags AS other ON other.project_id = base.project_id AND other.tag_id <> base.tag_id").
+		Joins("JOIN projects ON projects.id = base.project_id AND projects.is_public = ?", true).
+		Joins("JOIN tags ON tags.id = other.tag_id").
+		Where("base.tag_id = ?", tagID).
+		Group("tags.id, tags.name, tags.slug, tags.tag_type").
+		Order("project_count DESC, tags.slug").
+		Limit(limit).
+		Scan(&tags).Error
+	return tags, err
+}
+
+// tagCountQuery 查询标签及其公开项目数，没有项目的标签计数为0
+func tagCountQuery() *gorm.DB {
+	return database.DB.Table("tags").
+		Select("tags.id, tags.name, tags.slug, tags.tag_type, COUNT(projects.id) AS project_count").
+		Joins("LEFT JOIN project_tags ON project_tags.tag_id = tags.id").
+		Joins("LEFT JOIN projects ON projects.id = project_tags.project_id AND projects.is_public = ?", true).
+		Group("tags.id, tags.name, tags.slug, tags.tag_type")
+}
+
+// escapeLike 转义 LIKE 中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+type TokenRepository struct{}
+
+func NewTokenRepository() *TokenRepository {
+	return &TokenRepository{}
+}
+
+func (r *TokenRepository) Create(token *models.UserToken) error {
+	return database.DB.Create(token).Error
+}
+
+// Consume 原子地使用一个未过期且未使用过的令牌，令牌无效时返回nil
+func (r *TokenRepository) Consume(purpose, tokenHash string) (*models.UserToken, error) {
+	var token models.UserToken
+	err := database.DB.Where("purpose = ? AND token_hash = ?", purpose, tokenHash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.UsedAt != nil || now.After(token.ExpiresAt) {
+		return nil, nil
+	}
+
+	// 并发使用同一令牌时只有一个请求能更新成功
+	result := database.DB.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", token.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	token.UsedAt = &now
+	return &token, nil
+}
+
+// InvalidateUserTokens 使某个用户指定用途的所有未使用令牌失效
+func (r *TokenRepository) InvalidateUserTokens(userID int64, purpose string) error {
+	return database.DB.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
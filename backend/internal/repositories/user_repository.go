@@ -2,10 +2,13 @@ package repositories
 
 import (
 	"devswipe-backend/internal/models"
+	"devswipe-backend/pkg/auth"
 	"devswipe-backend/pkg/database"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct{}
@@ -155,3 +158,121 @@ func (r *UserRepository) GetFollowing(userID int64, limit, offset int) ([]models
 		Find(&users).Error
 	return users, err
 }
+
+// GetFollowerIDs 获取关注某个用户的所有用户ID
+func (r *UserRepository) GetFollowerIDs(userID int64) ([]int64, error) {
+	var ids []int64
+	err := database.DB.Model(&models.UserFollow{}).
+		Where("following_id = ?", userID).
+		Pluck("follower_id", &ids).Error
+	return ids, err
+}
+
+// Search 按用户名或邮箱搜索用户，role 不为空时按角色过滤，同时返回总数
+func (r *UserRepository) Search(keyword, role string, limit, offset int) ([]models.User, int64, error) {
+	query := database.DB.Model(&models.User{})
+	if keyword != "" {
+		query = query.Where("username LIKE ? OR email LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&users).Error
+	return users, total, err
+}
+
+// UpdateRole 修改用户角色
+func (r *UserRepository) UpdateRole(userID int64, role string) error {
+	return database.DB.Model(&models.User{}).Where("id = ?", userID).Update("role", role).Error
+}
+
+// PromoteToCreator 普通用户发布第一个项目后升级为创作者，已有更高角色的用户不变
+func (r *UserRepository) PromoteToCreator(userID int64) error {
+	return database.DB.Model(&models.User{}).
+		Where("id = ? AND role = ?", userID, auth.RoleUser).
+		Updates(map[string]interface{}{"role": auth.RoleCreator, "is_creator": true}).Error
+}
+
+// SetDeletionAt 设置或清除（传入nil）账户的计划删除时间
+func (r *UserRepository) SetDeletionAt(userID int64, deletionAt *time.Time) error {
+	return database.DB.Model(&models.User{}).Where("id = ?", userID).Update("deletion_at", deletionAt).Error
+}
+
+// GetDueForDeletion 获取冷静期已结束、需要删除数据的用户ID
+func (r *UserRepository) GetDueForDeletion(now time.Time, limit int) ([]int64, error) {
+	var ids []int64
+	err := database.DB.Model(&models.User{}).
+		Where("deletion_at IS NOT NULL AND deletion_at <= ?", now).
+		Order("deletion_at").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// IsFollowing 检查 followerID 是否关注了 followingID
+func (r *UserRepository) IsFollowing(followerID, followingID int64) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.UserFollow{}).
+		Where("follower_id = ? AND following_id = ?", followerID, followingID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SearchProfiles 按用户名、简介和技术栈搜索可公开检索的用户。
+// 仅关注者可见的用户只按用户名匹配，私密用户、注销中的用户和 excludeUserIDs 中的用户不会出现在结果中
+func (r *UserRepository) SearchProfiles(keyword string, excludeUserIDs []int64, limit, offset int) ([]models.User, error) {
+	pattern := "%" + keyword + "%"
+	var users []models.User
+	query := database.DB.
+		Where("deletion_at IS NULL AND profile_visibility <> ?", "private").
+		Where(database.DB.
+			Where("username LIKE ?", pattern).
+			Or("profile_visibility = ? AND (bio LIKE ? OR tech_stack LIKE ?)", "public", pattern, pattern))
+	if len(excludeUserIDs) > 0 {
+		query = query.Where("id NOT IN ?", excludeUserIDs)
+	}
+	err := query.
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "username = ? DESC", Vars: []interface{}{keyword}, WithoutParentheses: true}}).
+		Order("follower_count DESC").
+		Order("id").
+		Limit(limit).Offset(offset).
+		Find(&users).Error
+	return users, err
+}
+
+// GetUsernameRedirect 按旧用户名查找改名记录，不存在时返回 nil
+func (r *UserRepository) GetUsernameRedirect(oldUsername string) (*models.UsernameRedirect, error) {
+	var redirect models.UsernameRedirect
+	err := database.DB.Where("old_username = ?", oldUsername).First(&redirect).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &redirect, nil
+}
+
+// UpdateProfile 保存资料。用户名有变化时在同一事务中把旧用户名记录为跳转，
+// 改回自己以前用过的用户名时删除对应的跳转记录
+func (r *UserRepository) UpdateProfile(user *models.User, oldUsername string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if user.Username != oldUsername {
+			if err := tx.Where("old_username = ? AND user_id = ?", user.Username, user.ID).
+				Delete(&models.UsernameRedirect{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&models.UsernameRedirect{OldUsername: oldUsername, UserID: user.ID}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(user).Error
+	})
+}
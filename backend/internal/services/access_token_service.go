@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/pkg/auth"
+)
+
+// 审计日志中的个人访问令牌事件
+const (
+	AuditAccessTokenCreate = "access_token_create"
+	AuditAccessTokenRevoke = "access_token_revoke"
+)
+
+// maxAccessTokensPerUser 每个用户最多同时拥有的有效令牌数
+const maxAccessTokensPerUser = 20
+
+var (
+	ErrInvalidAccessToken  = errors.New("invalid or expired access token")
+	ErrAccessTokenNotFound = errors.New("access token not found")
+	ErrInvalidScope        = errors.New("invalid scope")
+	ErrTooManyAccessTokens = errors.New("too many access tokens, revoke an unused one first")
+)
+
+type AccessTokenService struct {
+	tokenRepo *repositories.AccessTokenRepository
+	userRepo  *repositories.UserRepository
+	auditRepo *repositories.AuditRepository
+}
+
+func NewAccessTokenService() *AccessTokenService {
+	return &AccessTokenService{
+		tokenRepo: repositories.NewAccessTokenRepository(),
+		userRepo:  repositories.NewUserRepository(),
+		auditRepo: repositories.NewAuditRepository(),
+	}
+}
+
+type CreateAccessTokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"min=0,max=365"` // 0 表示永不过期
+}
+
+// CreatedAccessToken 创建令牌的响应，明文令牌只在创建时返回一次
+type CreatedAccessToken struct {
+	Token       string                      `json:"token"`
+	AccessToken *models.PersonalAccessToken `json:"access_token"`
+}
+
+// Create 为用户创建个人访问令牌
+func (s *AccessTokenService) Create(userID int64, req *CreateAccessTokenRequest, ip string) (*CreatedAccessToken, error) {
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool)
+	for _, scope := range req.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !auth.ValidScope(scope) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	count, err := s.tokenRepo.CountActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxAccessTokensPerUser {
+		return nil, ErrTooManyAccessTokens
+	}
+
+	secret, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+	raw := auth.AccessTokenPrefix + secret
+
+	token := &models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    raw[:len(auth.AccessTokenPrefix)+8],
+		TokenHash: hashSecureToken(raw),
+		Scopes:    strings.Join(scopes, ","),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := s.tokenRepo.Create(token); err != nil {
+		return nil, err
+	}
+
+	recordAudit(s.auditRepo, &userID, AuditAccessTokenCreate, ip, fmt.Sprintf("token %d %q scopes=%s", token.ID, token.Name, token.Scopes))
+	return &CreatedAccessToken{Token: raw, AccessToken: token}, nil
+}
+
+// List 获取用户未撤销的令牌
+func (s *AccessTokenService) List(userID int64) ([]models.PersonalAccessToken, error) {
+	return s.tokenRepo.GetActiveByUser(userID)
+}
+
+// Revoke 撤销令牌，立即生效
+func (s *AccessTokenService) Revoke(userID, tokenID int64, ip string) error {
+	revoked, err := s.tokenRepo.Revoke(userID, tokenID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAccessTokenNotFound
+	}
+
+	recordAudit(s.auditRepo, &userID, AuditAccessTokenRevoke, ip, fmt.Sprintf("token %d", tokenID))
+	return nil
+}
+
+// Authenticate 校验个人访问令牌，返回令牌和所属用户，并记录最近使用时间
+func (s *AccessTokenService) Authenticate(raw, ip string) (*models.PersonalAccessToken, *models.User, error) {
+	token, err := s.tokenRepo.GetByHash(hashSecureToken(raw))
+	if err != nil {
+		return nil, nil, err
+	}
+	if token == nil || token.RevokedAt != nil || (token.ExpiresAt != nil && time.Now().After(*token.ExpiresAt)) {
+		return nil, nil, ErrInvalidAccessToken
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		return nil, nil, ErrInvalidAccessToken
+	}
+
+	if err := s.tokenRepo.Touch(token.ID, ip); err != nil {
+		log.Printf("Failed to record access token usage: %v", err)
+	}
+
+	return token, user, nil
+}
@@ -0,0 +1,430 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"devswipe-backend/internal/config"
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/pkg/cache"
+	"devswipe-backend/pkg/database"
+	"devswipe-backend/pkg/mailer"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// 审计日志中的账户注销事件
+const (
+	AuditDeletionRequest = "account_deletion_request"
+	AuditDeletionCancel  = "account_deletion_cancel"
+	AuditAccountDeleted  = "account_deleted"
+)
+
+const (
+	accountDeletionInterval = time.Hour
+	accountDeletionLockKey  = "account_deletion:lock"
+	accountDeletionBatch    = 20
+)
+
+var (
+	ErrDeletionConfirmation = errors.New("password or username confirmation is incorrect")
+	ErrDeletionNotRequested = errors.New("account deletion has not been requested")
+)
+
+// AccountDeletionService 处理账户注销：申请后进入冷静期，到期由定时任务删除用户的全部数据
+type AccountDeletionService struct {
+	userRepo       *repositories.UserRepository
+	tokenRepo      *repositories.AccessTokenRepository
+	auditRepo      *repositories.AuditRepository
+	sessionService *SessionService
+	cache          *cache.CacheManager
+	mailer         mailer.Mailer
+}
+
+func NewAccountDeletionService() *AccountDeletionService {
+	return &AccountDeletionService{
+		userRepo:       repositories.NewUserRepository(),
+		tokenRepo:      repositories.NewAccessTokenRepository(),
+		auditRepo:      repositories.NewAuditRepository(),
+		sessionService: NewSessionService(),
+		cache:          cache.NewCacheManager(),
+		mailer:         mailer.NewMailer(),
+	}
+}
+
+// DeleteAccountRequest 注销确认。设置了密码的账户需要输入密码，只绑定了第三方登录的账户需要输入用户名
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	Confirm  string `json:"confirm"`
+}
+
+// RequestDeletion 申请注销账户，返回计划删除数据的时间。
+// 其它设备上的会话和个人访问令牌立即失效，当前会话保留以便在冷静期内撤销
+func (s *AccountDeletionService) RequestDeletion(userID int64, req *DeleteAccountRequest, currentTokenID, ip string) (time.Time, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if user.PasswordHash != "" {
+		if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)) != nil {
+			return time.Time{}, ErrDeletionConfirmation
+		}
+	} else if req.Confirm != user.Username {
+		return time.Time{}, ErrDeletionConfirmation
+	}
+
+	// 重复申请时保持原计划，不重新计算冷静期
+	if user.DeletionAt != nil {
+		return *user.DeletionAt, nil
+	}
+
+	deletionAt := time.Now().Add(time.Duration(config.AppConfig.Account.DeletionGracePeriod) * 24 * time.Hour)
+	if err := s.userRepo.SetDeletionAt(userID, &deletionAt); err != nil {
+		return time.Time{}, err
+	}
+
+	if _, err := s.sessionService.RevokeAll(userID, currentTokenID, ip); err != nil {
+		log.Printf("Failed to revoke sessions after deletion request: %v", err)
+	}
+	if err := s.tokenRepo.RevokeAll(userID); err != nil {
+		log.Printf("Failed to revoke access tokens after deletion request: %v", err)
+	}
+
+	recordAudit(s.auditRepo, &userID, AuditDeletionRequest, ip, fmt.Sprintf("scheduled for %s", deletionAt.Format(time.RFC3339)))
+	s.notifyAsync(user, deletionAt)
+	return deletionAt, nil
+}
+
+// CancelDeletion 在冷静期内撤销注销申请
+func (s *AccountDeletionService) CancelDeletion(userID int64, ip string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.DeletionAt == nil {
+		return ErrDeletionNotRequested
+	}
+
+	if err := s.userRepo.SetDeletionAt(userID, nil); err != nil {
+		return err
+	}
+
+	recordAudit(s.auditRepo, &userID, AuditDeletionCancel, ip, "")
+	return nil
+}
+
+// StartScheduler 定时删除冷静期已结束的账户，ctx 取消时退出
+func (s *AccountDeletionService) StartScheduler(ctx context.Context) {
+	ticker := time.NewTicker(accountDeletionInterval)
+	defer ticker.Stop()
+
+	for {
+		// 多实例部署时只需要一个实例执行
+		if ok, err := s.cache.AcquireLock(ctx, accountDeletionLockKey, accountDeletionInterval/2); err == nil && ok {
+			if err := s.PurgeDue(time.Now()); err != nil {
+				log.Printf("Failed to purge deleted accounts: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeDue 删除所有冷静期已结束的账户，单个账户失败不影响其它账户
+func (s *AccountDeletionService) PurgeDue(now time.Time) error {
+	for {
+		userIDs, err := s.userRepo.GetDueForDeletion(now, accountDeletionBatch)
+		if err != nil {
+			return err
+		}
+
+		purged := 0
+		for _, userID := range userIDs {
+			if err := s.purge(userID); err != nil {
+				log.Printf("Failed to purge account %d: %v", userID, err)
+				continue
+			}
+			purged++
+		}
+
+		// 整批都失败时停止，等下一轮再试，避免反复处理同一批账户
+		if len(userIDs) < accountDeletionBatch || purged == 0 {
+			return nil
+		}
+	}
+}
+
+// purge 在一个事务中删除用户及其所有数据，并修正其他用户和项目上的计数
+func (s *AccountDeletionService) purge(userID int64) error {
+	var followerIDs []int64
+	var tokenIDs []string
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if followerIDs, err = purgeFollows(tx, userID); err != nil {
+			return err
+		}
+		if err := purgeProjects(tx, userID); err != nil {
+			return err
+		}
+		if err := purgeInteractions(tx, userID); err != nil {
+			return err
+		}
+		if err := purgeComments(tx, userID); err != nil {
+			return err
+		}
+		if err := purgeCollections(tx, userID); err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ? OR blocked_id = ?", userID, userID).Delete(&models.UserBlock{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? OR muted_id = ?", userID, userID).Delete(&models.UserMute{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.UserSession{}).Where("user_id = ?", userID).Pluck("token_id", &tokenIDs).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{
+			&models.UserPreferences{},
+			&models.UserToken{},
+			&models.UserIdentity{},
+			&models.PersonalAccessToken{},
+			&models.UserSession{},
+			&models.UserMFA{},
+			&models.MFARecoveryCode{},
+			&models.ExperimentExposure{},
+			&models.UsernameRedirect{},
+		} {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		// 审计日志保留事件本身，但不再能关联到用户
+		if err := tx.Model(&models.AuditLog{}).Where("user_id = ?", userID).
+			Updates(map[string]interface{}{"user_id": nil, "ip": ""}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.User{}, userID).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.sessionService.evict(tokenIDs...)
+
+	ctx := context.Background()
+	keys := []string{followingFeedCacheKey(userID)}
+	for _, followerID := range followerIDs {
+		keys = append(keys, followingFeedCacheKey(followerID))
+	}
+	if err := s.cache.DeleteKeys(ctx, keys...); err != nil {
+		log.Printf("Failed to invalidate following feeds of deleted account %d: %v", userID, err)
+	}
+	s.cache.DeleteByPattern(ctx, fmt.Sprintf("user_recommendations:%d:*", userID))
+	s.cache.DeleteByPattern(ctx, fmt.Sprintf("user_feed:%d:*", userID))
+
+	recordAudit(s.auditRepo, nil, AuditAccountDeleted, "", fmt.Sprintf("user %d", userID))
+	return nil
+}
+
+// purgeFollows 删除关注关系并修正对方的关注数、粉丝数，返回关注了该用户的用户ID
+func purgeFollows(tx *gorm.DB, userID int64) ([]int64, error) {
+	var followerIDs, followingIDs []int64
+	if err := tx.Model(&models.UserFollow{}).Where("following_id = ?", userID).Pluck("follower_id", &followerIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.UserFollow{}).Where("follower_id = ?", userID).Pluck("following_id", &followingIDs).Error; err != nil {
+		return nil, err
+	}
+
+	if len(followerIDs) > 0 {
+		if err := tx.Model(&models.User{}).Where("id IN ?", followerIDs).
+			Update("following_count", gorm.Expr("GREATEST(following_count - 1, 0)")).Error; err != nil {
+			return nil, err
+		}
+	}
+	if len(followingIDs) > 0 {
+		if err := tx.Model(&models.User{}).Where("id IN ?", followingIDs).
+			Update("follower_count", gorm.Expr("GREATEST(follower_count - 1, 0)")).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	err := tx.Where("follower_id = ? OR following_id = ?", userID, userID).Delete(&models.UserFollow{}).Error
+	return followerIDs, err
+}
+
+// purgeProjects 删除用户发布的项目，以及其他用户对这些项目的交互、评论和收藏
+func purgeProjects(tx *gorm.DB, userID int64) error {
+	var projectIDs []int64
+	if err := tx.Model(&models.Project{}).Where("user_id = ?", userID).Pluck("id", &projectIDs).Error; err != nil {
+		return err
+	}
+	if len(projectIDs) == 0 {
+		return nil
+	}
+
+	// 其他用户收藏夹中的这些项目
+	var items []struct {
+		CollectionID int64
+		Total        int
+	}
+	err := tx.Model(&models.CollectionItem{}).
+		Select("collection_id, COUNT(*) AS total").
+		Where("project_id IN ?", projectIDs).
+		Group("collection_id").
+		Scan(&items).Error
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := tx.Model(&models.Collection{}).Where("id = ?", item.CollectionID).
+			Update("item_count", gorm.Expr("GREATEST(item_count - ?, 0)", item.Total)).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, model := range []interface{}{
+		&models.CollectionItem{},
+		&models.ProjectTag{},
+		&models.UserInteraction{},
+		&models.Comment{},
+		&models.ProjectBanditStats{},
+		&models.ProjectUpdate{},
+	} {
+		if err := tx.Where("project_id IN ?", projectIDs).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Where("id IN ?", projectIDs).Delete(&models.Project{}).Error
+}
+
+// purgeInteractions 删除用户对其他项目的交互，并扣减项目统计
+func purgeInteractions(tx *gorm.DB, userID int64) error {
+	var counters []struct {
+		ProjectID       int64
+		InteractionType string
+		Total           int
+	}
+	err := tx.Model(&models.UserInteraction{}).
+		Select("project_id, interaction_type, COUNT(*) AS total").
+		Where("user_id = ?", userID).
+		Group("project_id, interaction_type").
+		Scan(&counters).Error
+	if err != nil {
+		return err
+	}
+
+	for _, counter := range counters {
+		column := interactionCounterColumn(counter.InteractionType)
+		if column == "" {
+			continue
+		}
+		if err := tx.Model(&models.Project{}).Where("id = ?", counter.ProjectID).
+			Update(column, gorm.Expr(fmt.Sprintf("GREATEST(%s - ?, 0)", column), counter.Total)).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Where("user_id = ?", userID).Delete(&models.UserInteraction{}).Error
+}
+
+// purgeComments 删除用户的评论及其下所有回复，并扣减项目评论数
+func purgeComments(tx *gorm.DB, userID int64) error {
+	var frontier []int64
+	if err := tx.Model(&models.Comment{}).Where("user_id = ?", userID).Pluck("id", &frontier).Error; err != nil {
+		return err
+	}
+
+	seen := make(map[int64]bool, len(frontier))
+	var commentIDs []int64
+	for len(frontier) > 0 {
+		var next []int64
+		for _, id := range frontier {
+			if !seen[id] {
+				seen[id] = true
+				commentIDs = append(commentIDs, id)
+				next = append(next, id)
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+
+		frontier = nil
+		if err := tx.Model(&models.Comment{}).Where("parent_id IN ?", next).Pluck("id", &frontier).Error; err != nil {
+			return err
+		}
+	}
+	if len(commentIDs) == 0 {
+		return nil
+	}
+
+	var counters []struct {
+		ProjectID int64
+		Total     int
+	}
+	err := tx.Model(&models.Comment{}).
+		Select("project_id, COUNT(*) AS total").
+		Where("id IN ?", commentIDs).
+		Group("project_id").
+		Scan(&counters).Error
+	if err != nil {
+		return err
+	}
+	for _, counter := range counters {
+		if err := tx.Model(&models.Project{}).Where("id = ?", counter.ProjectID).
+			Update("comment_count", gorm.Expr("GREATEST(comment_count - ?, 0)", counter.Total)).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Where("id IN ?", commentIDs).Delete(&models.Comment{}).Error
+}
+
+// purgeCollections 删除用户的收藏夹及其中的条目
+func purgeCollections(tx *gorm.DB, userID int64) error {
+	var collectionIDs []int64
+	if err := tx.Model(&models.Collection{}).Where("user_id = ?", userID).Pluck("id", &collectionIDs).Error; err != nil {
+		return err
+	}
+	if len(collectionIDs) == 0 {
+		return nil
+	}
+
+	if err := tx.Where("collection_id IN ?", collectionIDs).Delete(&models.CollectionItem{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("id IN ?", collectionIDs).Delete(&models.Collection{}).Error
+}
+
+// notifyAsync 发送注销确认邮件，告知删除时间和撤销方式，失败只记录日志
+func (s *AccountDeletionService) notifyAsync(user *models.User, deletionAt time.Time) {
+	go func() {
+		err := s.mailer.Send(context.Background(), mailer.Message{
+			To:      user.Email,
+			Subject: "你的 DevSwipe 账户将被注销",
+			Body: fmt.Sprintf("你好 %s，\n\n我们收到了注销账户的申请。你的账户和所有数据将于 %s 被永久删除。\n在此之前登录并在账户设置中撤销注销即可恢复账户。\n\n如果这不是你本人的操作，请尽快登录并修改密码。\n",
+				user.Username, deletionAt.Format("2006-01-02 15:04")),
+		})
+		if err != nil {
+			log.Printf("Failed to send deletion notice to user %d: %v", user.ID, err)
+		}
+	}()
+}
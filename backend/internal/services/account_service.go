@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"devswipe-backend/internal/config"
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/pkg/mailer"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 账户令牌的用途
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+var (
+	ErrInvalidAccountToken  = errors.New("invalid or expired token")
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+	ErrEmailNotVerified     = errors.New("email address must be verified before publishing projects")
+)
+
+type AccountService struct {
+	userRepo   *repositories.UserRepository
+	tokenRepo  *repositories.TokenRepository
+	loginGuard *LoginGuard
+	mailer     mailer.Mailer
+}
+
+func NewAccountService() *AccountService {
+	return &AccountService{
+		userRepo:   repositories.NewUserRepository(),
+		tokenRepo:  repositories.NewTokenRepository(),
+		loginGuard: NewLoginGuard(),
+		mailer:     mailer.NewMailer(),
+	}
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// RequestPasswordReset 发送密码重置邮件。邮箱不存在时同样返回成功，避免泄露账户是否存在
+func (s *AccountService) RequestPasswordReset(email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil || user == nil {
+		return nil
+	}
+
+	// 新令牌发放后，之前未使用的重置令牌全部失效
+	if err := s.tokenRepo.InvalidateUserTokens(user.ID, TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	ttl := time.Duration(config.AppConfig.Account.PasswordResetTTL) * time.Minute
+	token, err := s.issueToken(user.ID, TokenPurposePasswordReset, ttl)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", config.AppConfig.Account.FrontendURL, token)
+	return s.mailer.Send(context.Background(), mailer.Message{
+		To:      user.Email,
+		Subject: "重置你的 DevSwipe 密码",
+		Body: fmt.Sprintf("你好 %s，\n\n点击下面的链接重置密码，链接在 %d 分钟内有效且只能使用一次：\n%s\n\n如果这不是你本人的操作，请忽略这封邮件。\n",
+			user.Username, config.AppConfig.Account.PasswordResetTTL, link),
+	})
+}
+
+// ResetPassword 使用重置令牌设置新密码，同时解除登录锁定
+func (s *AccountService) ResetPassword(req *ResetPasswordRequest) error {
+	token, err := s.tokenRepo.Consume(TokenPurposePasswordReset, hashSecureToken(req.Token))
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return ErrInvalidAccountToken
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		return ErrInvalidAccountToken
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = string(hashedPassword)
+
+	// 能收到重置邮件也就证明了邮箱属于该用户
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	s.loginGuard.RecordSuccess(user.Email)
+
+	// 重置密码后其他设备上的会话全部失效
+	if _, err := NewSessionService().RevokeAll(user.ID, "", ""); err != nil {
+		log.Printf("Failed to revoke sessions after password reset: %v", err)
+	}
+	return nil
+}
+
+// SendVerificationEmail 向用户发送邮箱验证邮件
+func (s *AccountService) SendVerificationEmail(userID int64) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	if err := s.tokenRepo.InvalidateUserTokens(user.ID, TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	ttl := time.Duration(config.AppConfig.Account.EmailVerificationTTL) * time.Hour
+	token, err := s.issueToken(user.ID, TokenPurposeEmailVerification, ttl)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", config.AppConfig.Account.FrontendURL, token)
+	return s.mailer.Send(context.Background(), mailer.Message{
+		To:      user.Email,
+		Subject: "验证你的 DevSwipe 邮箱",
+		Body: fmt.Sprintf("你好 %s，\n\n点击下面的链接验证邮箱，链接在 %d 小时内有效：\n%s\n",
+			user.Username, config.AppConfig.Account.EmailVerificationTTL, link),
+	})
+}
+
+// VerifyEmail 使用验证令牌确认邮箱
+func (s *AccountService) VerifyEmail(tokenValue string) error {
+	token, err := s.tokenRepo.Consume(TokenPurposeEmailVerification, hashSecureToken(tokenValue))
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return ErrInvalidAccountToken
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		return ErrInvalidAccountToken
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	return s.userRepo.Update(user)
+}
+
+// CanPublish 根据配置检查用户是否可以发布项目
+func (s *AccountService) CanPublish(userID int64) error {
+	if !config.AppConfig.Account.RequireVerifiedEmailToPublish {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// issueToken 生成随机令牌，数据库中只保存其哈希
+func (s *AccountService) issueToken(userID int64, purpose string, ttl time.Duration) (string, error) {
+	token, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	record := &models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashSecureToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.tokenRepo.Create(record); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// sendVerificationAsync 注册后异步发送验证邮件，失败只记录日志
+func (s *AccountService) sendVerificationAsync(userID int64) {
+	go func() {
+		if err := s.SendVerificationEmail(userID); err != nil {
+			log.Printf("Failed to send verification email to user %d: %v", userID, err)
+		}
+	}()
+}
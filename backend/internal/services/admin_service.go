@@ -0,0 +1,214 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/pkg/auth"
+	"devswipe-backend/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// 审计日志中的管理操作事件
+const (
+	AuditRoleChange        = "role_change"
+	AuditAdminUnlock       = "admin_unlock"
+	AuditProjectVisibility = "admin_project_visibility"
+	AuditProjectDelete     = "admin_project_delete"
+	AuditCommentDelete     = "admin_comment_delete"
+)
+
+var (
+	ErrInvalidRole       = errors.New("invalid role")
+	ErrChangeOwnRole     = errors.New("cannot change your own role")
+	ErrCommentNotFound   = errors.New("comment not found")
+	ErrProjectNotFound   = errors.New("project not found")
+	ErrAdminUserNotFound = errors.New("user not found")
+)
+
+type AdminService struct {
+	userRepo    *repositories.UserRepository
+	projectRepo *repositories.ProjectRepository
+	auditRepo   *repositories.AuditRepository
+	loginGuard  *LoginGuard
+}
+
+func NewAdminService() *AdminService {
+	return &AdminService{
+		userRepo:    repositories.NewUserRepository(),
+		projectRepo: repositories.NewProjectRepository(),
+		auditRepo:   repositories.NewAuditRepository(),
+		loginGuard:  NewLoginGuard(),
+	}
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user creator moderator admin"`
+}
+
+type ProjectVisibilityRequest struct {
+	IsPublic *bool `json:"is_public" binding:"required"`
+}
+
+// BootstrapRoles 启动时为配置的邮箱授予管理员角色，并将历史上标记为创作者的用户迁移到 creator 角色。
+// 注册不要求验证邮箱，邮箱未验证的账户不授予管理员，避免他人抢先注册管理员邮箱
+func (s *AdminService) BootstrapRoles(adminEmails []string) error {
+	err := database.DB.Model(&models.User{}).
+		Where("is_creator = ? AND (role = ? OR role = '' OR role IS NULL)", true, auth.RoleUser).
+		Update("role", auth.RoleCreator).Error
+	if err != nil {
+		return err
+	}
+
+	for _, email := range adminEmails {
+		user, err := s.userRepo.GetByEmail(email)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			log.Printf("Admin email %s does not belong to any user, skipped", email)
+			continue
+		}
+		if user.EmailVerifiedAt == nil {
+			log.Printf("Admin email %s is not verified by user %d, skipped", email, user.ID)
+			continue
+		}
+		if user.Role != auth.RoleAdmin {
+			if err := s.userRepo.UpdateRole(user.ID, auth.RoleAdmin); err != nil {
+				return err
+			}
+			recordAudit(s.auditRepo, &user.ID, AuditRoleChange, "", fmt.Sprintf("%s -> %s by config", user.Role, auth.RoleAdmin))
+		}
+	}
+	return nil
+}
+
+func (s *AdminService) ListUsers(keyword, role string, limit, offset int) ([]models.User, int64, error) {
+	return s.userRepo.Search(strings.TrimSpace(keyword), role, limit, offset)
+}
+
+func (s *AdminService) GetUser(userID int64) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, ErrAdminUserNotFound
+	}
+	return user, nil
+}
+
+// UpdateRole 修改用户角色，并撤销该用户的全部会话：JWT中携带的旧角色立即失效，用户重新登录后使用新角色
+func (s *AdminService) UpdateRole(actorID, userID int64, role, ip string) error {
+	if !auth.ValidRole(role) {
+		return ErrInvalidRole
+	}
+	if actorID == userID {
+		return ErrChangeOwnRole
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return ErrAdminUserNotFound
+	}
+	if user.Role != role {
+		if err := s.userRepo.UpdateRole(userID, role); err != nil {
+			return err
+		}
+		recordAudit(s.auditRepo, &userID, AuditRoleChange, ip, fmt.Sprintf("%s -> %s by user %d", user.Role, role, actorID))
+	}
+
+	// 角色未变时同样撤销，撤销失败后可以用相同的角色重试
+	if _, err := NewSessionService().RevokeAll(userID, "", ip); err != nil {
+		return fmt.Errorf("role updated but failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// UnlockUser 管理员直接解除用户的登录锁定
+func (s *AdminService) UnlockUser(actorID, userID int64, ip string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return ErrAdminUserNotFound
+	}
+
+	s.loginGuard.RecordSuccess(user.Email)
+	recordAudit(s.auditRepo, &userID, AuditAdminUnlock, ip, fmt.Sprintf("unlocked by user %d", actorID))
+	return nil
+}
+
+// ResetMFA 为丢失认证器和恢复码的用户关闭两步验证
+func (s *AdminService) ResetMFA(actorID, userID int64, ip string) error {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return ErrAdminUserNotFound
+	}
+	return NewMFAService().Reset(actorID, userID, ip)
+}
+
+func (s *AdminService) GetAuditLogs(userID int64, limit, offset int) ([]models.AuditLog, error) {
+	return s.auditRepo.GetByUser(userID, limit, offset)
+}
+
+func (s *AdminService) ListProjects(keyword string, userID int64, limit, offset int) ([]models.Project, int64, error) {
+	return s.projectRepo.AdminSearch(strings.TrimSpace(keyword), userID, limit, offset)
+}
+
+// SetProjectVisibility 隐藏或恢复项目
+func (s *AdminService) SetProjectVisibility(actorID, projectID int64, isPublic bool, ip string) error {
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		return ErrProjectNotFound
+	}
+
+	err = database.DB.Model(&models.Project{}).Where("id = ?", projectID).Update("is_public", isPublic).Error
+	if err != nil {
+		return err
+	}
+
+	recordAudit(s.auditRepo, &project.UserID, AuditProjectVisibility, ip,
+		fmt.Sprintf("project %d is_public=%v by user %d", projectID, isPublic, actorID))
+	return nil
+}
+
+func (s *AdminService) DeleteProject(actorID, projectID int64, ip string) error {
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		return ErrProjectNotFound
+	}
+
+	if err := s.projectRepo.Delete(projectID); err != nil {
+		return err
+	}
+
+	recordAudit(s.auditRepo, &project.UserID, AuditProjectDelete, ip,
+		fmt.Sprintf("project %d %q deleted by user %d", projectID, project.Title, actorID))
+	return nil
+}
+
+// DeleteComment 删除评论及其回复，并更新项目的评论数
+func (s *AdminService) DeleteComment(actorID, commentID int64, ip string) error {
+	var comment models.Comment
+	if err := database.DB.First(&comment, commentID).Error; err != nil {
+		return ErrCommentNotFound
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? OR parent_id = ?", commentID, commentID).Delete(&models.Comment{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		return tx.Model(&models.Project{}).
+			Where("id = ?", comment.ProjectID).
+			Update("comment_count", gorm.Expr("GREATEST(comment_count - ?, 0)", result.RowsAffected)).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	recordAudit(s.auditRepo, &comment.UserID, AuditCommentDelete, ip,
+		fmt.Sprintf("comment %d on project %d deleted by user %d", commentID, comment.ProjectID, actorID))
+	return nil
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"log"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+)
+
+// recordAudit 写入一条审计日志，失败只记录到服务日志，不影响业务流程
+func recordAudit(auditRepo *repositories.AuditRepository, userID *int64, event, ip, detail string) {
+	entry := &models.AuditLog{
+		UserID: userID,
+		Event:  event,
+		IP:     ip,
+		Detail: detail,
+	}
+	if err := auditRepo.Create(entry); err != nil {
+		log.Printf("Failed to write audit log: %v", err)
+	}
+}
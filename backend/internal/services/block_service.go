@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/pkg/cache"
+)
+
+// hiddenUsersCacheTTL 需要对用户隐藏内容的作者列表的缓存时间，拉黑、静音变化时立即清除
+const hiddenUsersCacheTTL = 10 * time.Minute
+
+var (
+	ErrUserBlocked = errors.New("you cannot interact with this user")
+	ErrBlockSelf   = errors.New("cannot block yourself")
+	ErrMuteSelf    = errors.New("cannot mute yourself")
+	ErrNotBlocked  = errors.New("user is not blocked")
+	ErrNotMuted    = errors.New("user is not muted")
+)
+
+// BlockService 管理拉黑和静音。拉黑是双向的：双方不能互相关注、评论，也看不到对方的内容；
+// 静音只影响静音者自己的浏览流和评论列表
+type BlockService struct {
+	blockRepo *repositories.BlockRepository
+	userRepo  *repositories.UserRepository
+	cache     *cache.CacheManager
+}
+
+func NewBlockService() *BlockService {
+	return &BlockService{
+		blockRepo: repositories.NewBlockRepository(),
+		userRepo:  repositories.NewUserRepository(),
+		cache:     cache.NewCacheManager(),
+	}
+}
+
+// Block 拉黑用户并解除双方的关注关系
+func (s *BlockService) Block(userID, targetID int64) error {
+	if userID == targetID {
+		return ErrBlockSelf
+	}
+	if _, err := s.userRepo.GetByID(targetID); err != nil {
+		return ErrUserNotFound
+	}
+
+	if err := s.blockRepo.Block(userID, targetID); err != nil {
+		return err
+	}
+
+	// 关注关系和可见内容都变了，清除双方的动态流和浏览流缓存
+	s.invalidate(userID, targetID)
+	for _, id := range []int64{userID, targetID} {
+		if err := NewFollowingFeedService().Invalidate(id); err != nil {
+			log.Printf("Failed to invalidate following feed for user %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func (s *BlockService) Unblock(userID, targetID int64) error {
+	found, err := s.blockRepo.Unblock(userID, targetID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotBlocked
+	}
+
+	s.invalidate(userID, targetID)
+	return nil
+}
+
+// Mute 静音用户，对方的项目和评论不再出现在自己的浏览流和评论列表中
+func (s *BlockService) Mute(userID, targetID int64) error {
+	if userID == targetID {
+		return ErrMuteSelf
+	}
+	if _, err := s.userRepo.GetByID(targetID); err != nil {
+		return ErrUserNotFound
+	}
+
+	if err := s.blockRepo.Mute(userID, targetID); err != nil {
+		return err
+	}
+
+	s.invalidate(userID)
+	if err := NewFollowingFeedService().Invalidate(userID); err != nil {
+		log.Printf("Failed to invalidate following feed for user %d: %v", userID, err)
+	}
+	return nil
+}
+
+func (s *BlockService) Unmute(userID, targetID int64) error {
+	found, err := s.blockRepo.Unmute(userID, targetID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotMuted
+	}
+
+	s.invalidate(userID)
+	if err := NewFollowingFeedService().Invalidate(userID); err != nil {
+		log.Printf("Failed to invalidate following feed for user %d: %v", userID, err)
+	}
+	return nil
+}
+
+// GetBlockedUsers 获取拉黑的用户列表，只返回公开字段，与关注和粉丝列表相同
+func (s *BlockService) GetBlockedUsers(userID int64, limit, offset int) ([]UserSummary, error) {
+	users, err := s.blockRepo.GetBlockedUsers(userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return newUserSummaries(users), nil
+}
+
+// GetMutedUsers 获取静音的用户列表，只返回公开字段
+func (s *BlockService) GetMutedUsers(userID int64, limit, offset int) ([]UserSummary, error) {
+	users, err := s.blockRepo.GetMutedUsers(userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return newUserSummaries(users), nil
+}
+
+// Relation 查看者与另一个用户之间的拉黑、静音状态
+func (s *BlockService) Relation(viewerID, userID int64) (blocked, blockedBy, muted bool, err error) {
+	if blocked, err = s.blockRepo.IsBlocked(viewerID, userID); err != nil {
+		return
+	}
+	if blockedBy, err = s.blockRepo.IsBlocked(userID, viewerID); err != nil {
+		return
+	}
+	muted, err = s.blockRepo.IsMuted(viewerID, userID)
+	return
+}
+
+// CheckInteraction 两个用户之间有任意一方拉黑另一方时返回 ErrUserBlocked，用于关注、评论和回复前的检查
+func (s *BlockService) CheckInteraction(userID, otherID int64) error {
+	if userID == otherID {
+		return nil
+	}
+	blocked, err := s.blockRepo.IsBlockedEither(userID, otherID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrUserBlocked
+	}
+	return nil
+}
+
+// HiddenUsers 获取内容需要对该用户隐藏的作者，未登录用户返回空集合
+func (s *BlockService) HiddenUsers(userID int64) (map[int64]bool, error) {
+	if userID == 0 {
+		return map[int64]bool{}, nil
+	}
+
+	ctx := context.Background()
+	var ids []int64
+	if err := s.cache.Get(ctx, hiddenUsersCacheKey(userID), &ids); err != nil {
+		ids, err = s.blockRepo.GetHiddenUserIDs(userID)
+		if err != nil {
+			return nil, err
+		}
+		s.cache.Set(ctx, hiddenUsersCacheKey(userID), ids, hiddenUsersCacheTTL)
+	}
+
+	hidden := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		hidden[id] = true
+	}
+	return hidden, nil
+}
+
+// HiddenUserIDs 与 HiddenUsers 相同，返回切片形式，便于作为查询条件
+func (s *BlockService) HiddenUserIDs(userID int64) ([]int64, error) {
+	hidden, err := s.HiddenUsers(userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(hidden))
+	for id := range hidden {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// invalidate 清除用户的隐藏列表和浏览流缓存
+func (s *BlockService) invalidate(userIDs ...int64) {
+	ctx := context.Background()
+	for _, userID := range userIDs {
+		if err := s.cache.Delete(ctx, hiddenUsersCacheKey(userID)); err != nil {
+			log.Printf("Failed to invalidate hidden users for user %d: %v", userID, err)
+		}
+		s.cache.DeleteByPattern(ctx, fmt.Sprintf("user_feed:%d:*", userID))
+	}
+}
+
+// FilterHiddenAuthors 移除隐藏作者发布的项目
+func FilterHiddenAuthors(projects []models.Project, hidden map[int64]bool) []models.Project {
+	if len(hidden) == 0 {
+		return projects
+	}
+
+	filtered := make([]models.Project, 0, len(projects))
+	for _, project := range projects {
+		if !hidden[project.UserID] {
+			filtered = append(filtered, project)
+		}
+	}
+	return filtered
+}
+
+func hiddenUsersCacheKey(userID int64) string {
+	return fmt.Sprintf("hidden_users:%d", userID)
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"devswipe-backend/internal/models"
+)
+
+func authorProjectIDs(projects []models.Project) []int64 {
+	ids := make([]int64, 0, len(projects))
+	for _, project := range projects {
+		ids = append(ids, project.ID)
+	}
+	return ids
+}
+
+func TestFilterHiddenAuthors(t *testing.T) {
+	projects := []models.Project{
+		{ID: 1, UserID: 10},
+		{ID: 2, UserID: 20},
+		{ID: 3, UserID: 30},
+		{ID: 4, UserID: 20},
+	}
+
+	got := authorProjectIDs(FilterHiddenAuthors(projects, map[int64]bool{20: true}))
+	if want := []int64{1, 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("FilterHiddenAuthors() = %v, want %v", got, want)
+	}
+
+	// 没有隐藏作者时原样返回
+	got = authorProjectIDs(FilterHiddenAuthors(projects, map[int64]bool{}))
+	if want := []int64{1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("FilterHiddenAuthors() = %v, want %v", got, want)
+	}
+}
+
+func TestProfileAccessIsRestricted(t *testing.T) {
+	tests := []struct {
+		name       string
+		access     profileAccess
+		visibility string
+		want       bool
+	}{
+		{"public", profileAccess{}, ProfileVisibilityPublic, false},
+		{"public but blocked by viewer", profileAccess{blocked: true}, ProfileVisibilityPublic, true},
+		{"public and muted", profileAccess{muted: true}, ProfileVisibilityPublic, false},
+		{"followers only, not following", profileAccess{}, ProfileVisibilityFollowers, true},
+		{"followers only, following", profileAccess{isFollowing: true}, ProfileVisibilityFollowers, false},
+		{"followers only, follows viewer back only", profileAccess{followsYou: true}, ProfileVisibilityFollowers, true},
+		{"private, following", profileAccess{isFollowing: true}, ProfileVisibilityPrivate, true},
+		{"private, self", profileAccess{isSelf: true}, ProfileVisibilityPrivate, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.access.isRestricted(tt.visibility); got != tt.want {
+			t.Errorf("%s: isRestricted(%q) = %v, want %v", tt.name, tt.visibility, got, tt.want)
+		}
+	}
+}
+
+func TestHiddenUsersAnonymous(t *testing.T) {
+	// 未登录用户不查询拉黑关系，所有作者都可见
+	hidden, err := NewBlockService().HiddenUsers(0)
+	if err != nil {
+		t.Fatalf("HiddenUsers(0) error = %v", err)
+	}
+	if len(hidden) != 0 {
+		t.Fatalf("HiddenUsers(0) = %v, want empty", hidden)
+	}
+}
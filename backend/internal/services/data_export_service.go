@@ -0,0 +1,270 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"time"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/pkg/database"
+)
+
+// DataExportService 导出用户的个人数据，供用户下载自己的全部资料
+type DataExportService struct {
+	userRepo     *repositories.UserRepository
+	identityRepo *repositories.IdentityRepository
+}
+
+func NewDataExportService() *DataExportService {
+	return &DataExportService{
+		userRepo:     repositories.NewUserRepository(),
+		identityRepo: repositories.NewIdentityRepository(),
+	}
+}
+
+// UserDataExport 导出文件的内容。各部分使用独立的结构，不随内部模型的关联字段变化
+type UserDataExport struct {
+	ExportedAt   time.Time             `json:"exported_at"`
+	Profile      *models.User          `json:"profile"`
+	Preferences  ExportedPreferences   `json:"preferences"`
+	Identities   []models.UserIdentity `json:"identities"`
+	Projects     []ExportedProject     `json:"projects"`
+	Interactions []ExportedInteraction `json:"interactions"`
+	Comments     []ExportedComment     `json:"comments"`
+	Collections  []ExportedCollection  `json:"collections"`
+	Followers    []ExportedFollow      `json:"followers"`
+	Following    []ExportedFollow      `json:"following"`
+}
+
+type ExportedPreferences struct {
+	PreferredTags     string `json:"preferred_tags"`
+	FollowedTags      string `json:"followed_tags"`
+	MutedTags         string `json:"muted_tags"`
+	PreferredStatuses string `json:"preferred_statuses"`
+}
+
+type ExportedProject struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	CoverImage  string    `json:"cover_image"`
+	ImageURLs   string    `json:"image_urls"`
+	ProjectURL  string    `json:"project_url"`
+	Status      string    `json:"status"`
+	IsPublic    bool      `json:"is_public"`
+	Tags        []string  `json:"tags"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ExportedInteraction struct {
+	ProjectID          int64     `json:"project_id"`
+	ProjectTitle       string    `json:"project_title"`
+	InteractionType    string    `json:"interaction_type"`
+	StructuredFeedback string    `json:"structured_feedback,omitempty"`
+	ViewDuration       float64   `json:"view_duration"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+type ExportedComment struct {
+	ID        int64     `json:"id"`
+	ProjectID int64     `json:"project_id"`
+	ParentID  *int64    `json:"parent_id,omitempty"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportedCollection struct {
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	IsPublic    bool                     `json:"is_public"`
+	Items       []ExportedCollectionItem `json:"items"`
+	CreatedAt   time.Time                `json:"created_at"`
+}
+
+type ExportedCollectionItem struct {
+	ProjectID int64     `json:"project_id"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportedFollow struct {
+	UserID    int64     `json:"user_id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Export 收集用户的全部个人数据
+func (s *DataExportService) Export(userID int64) (*UserDataExport, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	preferences, err := s.userRepo.GetUserPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := s.identityRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &UserDataExport{
+		ExportedAt: time.Now(),
+		Profile:    user,
+		Preferences: ExportedPreferences{
+			PreferredTags:     preferences.PreferredTags,
+			FollowedTags:      preferences.FollowedTags,
+			MutedTags:         preferences.MutedTags,
+			PreferredStatuses: preferences.PreferredStatuses,
+		},
+		Identities: identities,
+	}
+
+	var projects []models.Project
+	if err := database.DB.Preload("Tags").Where("user_id = ?", userID).Order("id").Find(&projects).Error; err != nil {
+		return nil, err
+	}
+	export.Projects = make([]ExportedProject, 0, len(projects))
+	for _, project := range projects {
+		tags := make([]string, 0, len(project.Tags))
+		for _, tag := range project.Tags {
+			tags = append(tags, tag.TagName)
+		}
+		export.Projects = append(export.Projects, ExportedProject{
+			ID:          project.ID,
+			Title:       project.Title,
+			Description: project.Description,
+			CoverImage:  project.CoverImage,
+			ImageURLs:   project.ImageURLs,
+			ProjectURL:  project.ProjectURL,
+			Status:      project.Status,
+			IsPublic:    project.IsPublic,
+			Tags:        tags,
+			CreatedAt:   project.CreatedAt,
+			UpdatedAt:   project.UpdatedAt,
+		})
+	}
+
+	var interactions []models.UserInteraction
+	if err := database.DB.Preload("Project").Where("user_id = ?", userID).Order("id").Find(&interactions).Error; err != nil {
+		return nil, err
+	}
+	export.Interactions = make([]ExportedInteraction, 0, len(interactions))
+	for _, interaction := range interactions {
+		export.Interactions = append(export.Interactions, ExportedInteraction{
+			ProjectID:          interaction.ProjectID,
+			ProjectTitle:       interaction.Project.Title,
+			InteractionType:    interaction.InteractionType,
+			StructuredFeedback: interaction.StructuredFeedback,
+			ViewDuration:       interaction.ViewDuration,
+			CreatedAt:          interaction.CreatedAt,
+		})
+	}
+
+	var comments []models.Comment
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	export.Comments = make([]ExportedComment, 0, len(comments))
+	for _, comment := range comments {
+		export.Comments = append(export.Comments, ExportedComment{
+			ID:        comment.ID,
+			ProjectID: comment.ProjectID,
+			ParentID:  comment.ParentID,
+			Content:   comment.Content,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+
+	var collections []models.Collection
+	if err := database.DB.Preload("Items").Where("user_id = ?", userID).Order("id").Find(&collections).Error; err != nil {
+		return nil, err
+	}
+	export.Collections = make([]ExportedCollection, 0, len(collections))
+	for _, collection := range collections {
+		items := make([]ExportedCollectionItem, 0, len(collection.Items))
+		for _, item := range collection.Items {
+			items = append(items, ExportedCollectionItem{
+				ProjectID: item.ProjectID,
+				Notes:     item.Notes,
+				CreatedAt: item.CreatedAt,
+			})
+		}
+		export.Collections = append(export.Collections, ExportedCollection{
+			Name:        collection.Name,
+			Description: collection.Description,
+			IsPublic:    collection.IsPublic,
+			Items:       items,
+			CreatedAt:   collection.CreatedAt,
+		})
+	}
+
+	if export.Followers, err = exportFollows(userID, "following_id", "follower_id"); err != nil {
+		return nil, err
+	}
+	if export.Following, err = exportFollows(userID, "follower_id", "following_id"); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// exportFollows 查询关注关系，column 为该用户所在的列，otherColumn 为对方所在的列
+func exportFollows(userID int64, column, otherColumn string) ([]ExportedFollow, error) {
+	follows := []ExportedFollow{}
+	err := database.DB.Table("user_follows").
+		Select("users.id AS user_id, users.username, user_follows.created_at").
+		Joins("JOIN users ON users.id = user_follows."+otherColumn).
+		Where("user_follows."+column+" = ?", userID).
+		Order("user_follows.id").
+		Scan(&follows).Error
+	return follows, err
+}
+
+// WriteZip 将导出内容按部分写入ZIP，每部分一个JSON文件
+func (e *UserDataExport) WriteZip(w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"profile.json", map[string]interface{}{
+			"exported_at": e.ExportedAt,
+			"profile":     e.Profile,
+			"preferences": e.Preferences,
+			"identities":  e.Identities,
+		}},
+		{"projects.json", e.Projects},
+		{"interactions.json", e.Interactions},
+		{"comments.json", e.Comments},
+		{"collections.json", e.Collections},
+		{"follows.json", map[string]interface{}{
+			"followers": e.Followers,
+			"following": e.Following,
+		}},
+	}
+
+	for _, file := range files {
+		data, err := json.MarshalIndent(file.content, "", "  ")
+		if err != nil {
+			return err
+		}
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: e.ExportedAt,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := entry.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
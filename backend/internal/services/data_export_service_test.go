@@ -0,0 +1,75 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"devswipe-backend/internal/models"
+)
+
+func TestUserDataExportWriteZip(t *testing.T) {
+	export := &UserDataExport{
+		ExportedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Profile:    &models.User{ID: 7, Username: "alice"},
+		Projects: []ExportedProject{
+			{ID: 1, Title: "DevSwipe", Tags: []string{"go"}},
+		},
+		Comments:  []ExportedComment{},
+		Followers: []ExportedFollow{{UserID: 8, Username: "bob"}},
+		Following: []ExportedFollow{},
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteZip(&buf); err != nil {
+		t.Fatalf("WriteZip() error = %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+
+	contents := make(map[string][]byte)
+	var names []string
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", file.Name, err)
+		}
+		names = append(names, file.Name)
+		contents[file.Name] = data
+	}
+
+	wantNames := []string{"profile.json", "projects.json", "interactions.json", "comments.json", "collections.json", "follows.json"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("files = %v, want %v", names, wantNames)
+	}
+
+	var projects []ExportedProject
+	if err := json.Unmarshal(contents["projects.json"], &projects); err != nil {
+		t.Fatalf("projects.json: %v", err)
+	}
+	if len(projects) != 1 || projects[0].Title != "DevSwipe" {
+		t.Fatalf("projects = %+v", projects)
+	}
+
+	var follows struct {
+		Followers []ExportedFollow `json:"followers"`
+	}
+	if err := json.Unmarshal(contents["follows.json"], &follows); err != nil {
+		t.Fatalf("follows.json: %v", err)
+	}
+	if len(follows.Followers) != 1 || follows.Followers[0].Username != "bob" {
+		t.Fatalf("followers = %+v", follows.Followers)
+	}
+}
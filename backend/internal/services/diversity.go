@@ -0,0 +1,87 @@
+package services
+
+// DiversifyScores 使用最大边际相关性（MMR）对已按分数降序排列的推荐结果重排。
+// strength 为多样性强度（0 表示不重排，1 表示只考虑多样性），
+// window 为计算相似度时回看的已选项目数量，通常等于一页的大小。
+func DiversifyScores(ranked []RecommendationScore, strength float64, window int) []RecommendationScore {
+	if strength <= 0 || window <= 0 || len(ranked) < 2 {
+		return ranked
+	}
+	if strength > 1 {
+		strength = 1
+	}
+
+	// 将分数归一化到[0, 1]，与相似度处于同一量纲
+	maxScore := 0.0
+	for _, rec := range ranked {
+		if rec.Score > maxScore {
+			maxScore = rec.Score
+		}
+	}
+
+	remaining := make([]RecommendationScore, len(ranked))
+	copy(remaining, ranked)
+	result := make([]RecommendationScore, 0, len(ranked))
+
+	for len(remaining) > 0 {
+		start := max(0, len(result)-window)
+		recent := result[start:]
+
+		best, bestValue := 0, 0.0
+		for i, candidate := range remaining {
+			relevance := 0.0
+			if maxScore > 0 {
+				relevance = candidate.Score / maxScore
+			}
+
+			maxSimilarity := 0.0
+			for _, selected := range recent {
+				maxSimilarity = max(maxSimilarity, recommendationSimilarity(candidate, selected))
+			}
+
+			value := (1-strength)*relevance - strength*maxSimilarity
+			// 相同值时保留原有排序
+			if i == 0 || value > bestValue {
+				best, bestValue = i, value
+			}
+		}
+
+		result = append(result, remaining[best])
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+
+	return result
+}
+
+// recommendationSimilarity 计算两个推荐项目的相似度：同一作者为1，否则为标签的Jaccard系数
+func recommendationSimilarity(a, b RecommendationScore) float64 {
+	if a.CreatorID != 0 && a.CreatorID == b.CreatorID {
+		return 1
+	}
+	if len(a.Tags) == 0 || len(b.Tags) == 0 {
+		return 0
+	}
+
+	tags := make(map[string]bool, len(a.Tags))
+	for _, tag := range a.Tags {
+		tags[tagKey(tag)] = true
+	}
+
+	intersection := 0
+	union := len(tags)
+	seen := make(map[string]bool, len(b.Tags))
+	for _, tag := range b.Tags {
+		key := tagKey(tag)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if tags[key] {
+			intersection++
+		} else {
+			union++
+		}
+	}
+
+	return float64(intersection) / float64(union)
+}
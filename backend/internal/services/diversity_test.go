@@ -0,0 +1,114 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func projectIDs(scores []RecommendationScore) []int64 {
+	ids := make([]int64, 0, len(scores))
+	for _, rec := range scores {
+		ids = append(ids, rec.ProjectID)
+	}
+	return ids
+}
+
+func TestDiversifyScoresDisabledKeepsOrder(t *testing.T) {
+	ranked := []RecommendationScore{
+		{ProjectID: 1, Score: 0.9, CreatorID: 10},
+		{ProjectID: 2, Score: 0.8, CreatorID: 10},
+		{ProjectID: 3, Score: 0.7, CreatorID: 20},
+	}
+
+	got := projectIDs(DiversifyScores(ranked, 0, 6))
+	want := []int64{1, 2, 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("DiversifyScores() = %v, want %v", got, want)
+	}
+}
+
+func TestDiversifyScoresSpreadsSameCreator(t *testing.T) {
+	ranked := []RecommendationScore{
+		{ProjectID: 1, Score: 0.90, CreatorID: 10},
+		{ProjectID: 2, Score: 0.88, CreatorID: 10},
+		{ProjectID: 3, Score: 0.86, CreatorID: 10},
+		{ProjectID: 4, Score: 0.70, CreatorID: 20},
+		{ProjectID: 5, Score: 0.65, CreatorID: 30},
+	}
+
+	got := projectIDs(DiversifyScores(ranked, 0.5, 3))
+	want := []int64{1, 4, 5, 2, 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("DiversifyScores() = %v, want %v", got, want)
+	}
+}
+
+func TestDiversifyScoresSpreadsOverlappingTags(t *testing.T) {
+	ranked := []RecommendationScore{
+		{ProjectID: 1, Score: 0.90, CreatorID: 1, Tags: []string{"React", "Go"}},
+		{ProjectID: 2, Score: 0.85, CreatorID: 2, Tags: []string{"react", "go"}},
+		{ProjectID: 3, Score: 0.80, CreatorID: 3, Tags: []string{"Python", "AI"}},
+	}
+
+	got := projectIDs(DiversifyScores(ranked, 0.5, 6))
+	want := []int64{1, 3, 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("DiversifyScores() = %v, want %v", got, want)
+	}
+}
+
+func TestDiversifyScoresWeakStrengthPrefersRelevance(t *testing.T) {
+	ranked := []RecommendationScore{
+		{ProjectID: 1, Score: 0.90, CreatorID: 10},
+		{ProjectID: 2, Score: 0.88, CreatorID: 10},
+		{ProjectID: 3, Score: 0.10, CreatorID: 20},
+	}
+
+	// 相关性差距远大于多样性收益时，不应把低分项目提到前面
+	got := projectIDs(DiversifyScores(ranked, 0.1, 6))
+	want := []int64{1, 2, 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("DiversifyScores() = %v, want %v", got, want)
+	}
+}
+
+func TestDiversifyScoresWindowLimitsLookback(t *testing.T) {
+	ranked := []RecommendationScore{
+		{ProjectID: 1, Score: 0.90, CreatorID: 10},
+		{ProjectID: 2, Score: 0.85, CreatorID: 20},
+		{ProjectID: 3, Score: 0.80, CreatorID: 10},
+		{ProjectID: 4, Score: 0.50, CreatorID: 30},
+	}
+
+	// 窗口为1时只与上一个项目比较，作者10的第二个项目无需被推后
+	got := projectIDs(DiversifyScores(ranked, 0.5, 1))
+	want := []int64{1, 2, 3, 4}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("DiversifyScores() = %v, want %v", got, want)
+	}
+}
+
+func TestRecommendationSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b RecommendationScore
+		want float64
+	}{
+		{"same creator", RecommendationScore{CreatorID: 1}, RecommendationScore{CreatorID: 1}, 1},
+		{"no tags", RecommendationScore{CreatorID: 1}, RecommendationScore{CreatorID: 2}, 0},
+		{
+			"half overlap",
+			RecommendationScore{CreatorID: 1, Tags: []string{"React", "Go"}},
+			RecommendationScore{CreatorID: 2, Tags: []string{"react", "Python", "Go"}},
+			2.0 / 3.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recommendationSimilarity(tt.a, tt.b); got != tt.want {
+				t.Errorf("recommendationSimilarity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
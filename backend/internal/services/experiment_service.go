@@ -0,0 +1,301 @@
+package services
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"devswipe-backend/internal/config"
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+)
+
+// 95%置信区间对应的z值
+const confidenceZ = 1.96
+
+var (
+	ErrExperimentNameRequired = errors.New("experiment name is required")
+	ErrExperimentNotFound     = errors.New("experiment not found")
+)
+
+type ExperimentService struct {
+	experimentRepo *repositories.ExperimentRepository
+}
+
+func NewExperimentService() *ExperimentService {
+	return &ExperimentService{
+		experimentRepo: repositories.NewExperimentRepository(),
+	}
+}
+
+// ExperimentAssignment 用户（或会话）在当前实验中的分组
+type ExperimentAssignment struct {
+	Experiment string             `json:"experiment"`
+	Variant    string             `json:"variant"`
+	UnitID     string             `json:"-"`
+	Strategy   string             `json:"-"`
+	Params     map[string]float64 `json:"-"`
+}
+
+// RankingParams 返回该分组对应的排序参数
+func (a *ExperimentAssignment) RankingParams() RankingParams {
+	params := DefaultRankingParams()
+	if a == nil {
+		return params
+	}
+	return params.WithOverrides(a.Experiment+"."+a.Variant, a.Params)
+}
+
+type VariantReport struct {
+	Variant      string     `json:"variant"`
+	Exposures    int64      `json:"exposures"`
+	ExposedUnits int64      `json:"exposed_units"`
+	Swipes       int64      `json:"swipes"`
+	Likes        int64      `json:"likes"`
+	LikeRate     float64    `json:"like_rate"`
+	LikeRateCI   [2]float64 `json:"like_rate_ci"`
+	DwellSamples int64      `json:"dwell_samples"`
+	AvgDwell     float64    `json:"avg_dwell"`
+	DwellCI      [2]float64 `json:"dwell_ci"`
+}
+
+type ExperimentReport struct {
+	Experiment string          `json:"experiment"`
+	Confidence float64         `json:"confidence"`
+	Variants   []VariantReport `json:"variants"`
+}
+
+// runningExperiment 返回当前生效的实验（第一个启用且有流量的实验）
+func runningExperiment() *config.ExperimentConfig {
+	for i := range config.AppConfig.Experiments {
+		exp := &config.AppConfig.Experiments[i]
+		if !exp.Enabled {
+			continue
+		}
+		for _, variant := range exp.Variants {
+			if variant.Weight > 0 {
+				return exp
+			}
+		}
+	}
+	return nil
+}
+
+// Assign 为用户或会话分配实验变体，没有生效实验或无法分桶时返回nil
+func (s *ExperimentService) Assign(userID int64, sessionID string) *ExperimentAssignment {
+	exp := runningExperiment()
+	if exp == nil {
+		return nil
+	}
+
+	unitID := ""
+	if exp.BucketBy == "session" && sessionID != "" {
+		unitID = "s:" + sessionID
+	} else if userID > 0 {
+		unitID = "u:" + strconv.FormatInt(userID, 10)
+	} else if sessionID != "" {
+		unitID = "s:" + sessionID
+	}
+	if unitID == "" {
+		return nil
+	}
+
+	variant := bucketVariant(exp.Name, exp.Variants, unitID)
+	if variant == nil {
+		return nil
+	}
+
+	return &ExperimentAssignment{
+		Experiment: exp.Name,
+		Variant:    variant.Name,
+		UnitID:     unitID,
+		Strategy:   variant.Strategy,
+		Params:     variant.Params,
+	}
+}
+
+// ExposedAssignment 返回用户或会话最近一次曝光时所在的分组，交互按该分组归因，
+// 避免交互时重新分桶（例如按会话分桶但交互没有会话ID）把交互记到其它变体上。
+// 先按会话查找，会话内没有曝光时再按用户查找；从未曝光过时返回nil
+func (s *ExperimentService) ExposedAssignment(userID int64, sessionID string) (*ExperimentAssignment, error) {
+	exp := runningExperiment()
+	if exp == nil {
+		return nil, nil
+	}
+
+	var exposure *models.ExperimentExposure
+	var err error
+	if sessionID != "" {
+		if exposure, err = s.experimentRepo.GetLatestExposure(exp.Name, 0, sessionID); err != nil {
+			return nil, err
+		}
+	}
+	if exposure == nil && userID > 0 {
+		if exposure, err = s.experimentRepo.GetLatestExposure(exp.Name, userID, ""); err != nil {
+			return nil, err
+		}
+	}
+	if exposure == nil {
+		return nil, nil
+	}
+
+	return &ExperimentAssignment{
+		Experiment: exposure.Experiment,
+		Variant:    exposure.Variant,
+		UnitID:     exposure.UnitID,
+	}, nil
+}
+
+// bucketVariant 按实验名和分桶单位做确定性哈希，按权重选取变体
+func bucketVariant(experiment string, variants []config.VariantConfig, unitID string) *config.VariantConfig {
+	total := 0
+	for _, variant := range variants {
+		if variant.Weight > 0 {
+			total += variant.Weight
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(experiment + ":" + unitID))
+	bucket := int(h.Sum32() % uint32(total))
+
+	for i := range variants {
+		if variants[i].Weight <= 0 {
+			continue
+		}
+		if bucket < variants[i].Weight {
+			return &variants[i]
+		}
+		bucket -= variants[i].Weight
+	}
+	return nil
+}
+
+// LogExposure 记录一次实验曝光
+func (s *ExperimentService) LogExposure(assignment *ExperimentAssignment, userID int64, sessionID string) error {
+	if assignment == nil {
+		return nil
+	}
+	return s.experimentRepo.CreateExposure(&models.ExperimentExposure{
+		Experiment: assignment.Experiment,
+		Variant:    assignment.Variant,
+		UnitID:     assignment.UnitID,
+		UserID:     userID,
+		SessionID:  sessionID,
+	})
+}
+
+// GetReport 汇总实验各变体的喜爱率和停留时长，附带95%置信区间。
+// 实验不在配置中且没有任何数据时返回 ErrExperimentNotFound
+func (s *ExperimentService) GetReport(experiment string) (*ExperimentReport, error) {
+	if strings.TrimSpace(experiment) == "" {
+		return nil, ErrExperimentNameRequired
+	}
+
+	exposureStats, err := s.experimentRepo.GetExposureStats(experiment)
+	if err != nil {
+		return nil, err
+	}
+
+	interactionStats, err := s.experimentRepo.GetInteractionStats(experiment)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(exposureStats) == 0 && len(interactionStats) == 0 && !configuredExperiment(experiment) {
+		return nil, ErrExperimentNotFound
+	}
+
+	reports := make(map[string]*VariantReport)
+	variantReport := func(name string) *VariantReport {
+		if _, ok := reports[name]; !ok {
+			reports[name] = &VariantReport{Variant: name}
+		}
+		return reports[name]
+	}
+
+	for _, stat := range exposureStats {
+		report := variantReport(stat.Variant)
+		report.Exposures = stat.Exposures
+		report.ExposedUnits = stat.Units
+	}
+
+	for _, stat := range interactionStats {
+		report := variantReport(stat.Variant)
+		report.Swipes = stat.Swipes
+		report.Likes = stat.Likes
+		report.DwellSamples = stat.DwellSamples
+
+		if stat.Swipes > 0 {
+			report.LikeRate = float64(stat.Likes) / float64(stat.Swipes)
+			report.LikeRateCI = wilsonInterval(stat.Likes, stat.Swipes, confidenceZ)
+		}
+		if stat.DwellSamples > 0 {
+			report.AvgDwell = stat.DwellSum / float64(stat.DwellSamples)
+			report.DwellCI = meanInterval(stat.DwellSamples, stat.DwellSum, stat.DwellSqSum, confidenceZ)
+		}
+	}
+
+	result := &ExperimentReport{
+		Experiment: experiment,
+		Confidence: 0.95,
+		Variants:   make([]VariantReport, 0, len(reports)),
+	}
+	for _, report := range reports {
+		result.Variants = append(result.Variants, *report)
+	}
+	sort.Slice(result.Variants, func(i, j int) bool {
+		return result.Variants[i].Variant < result.Variants[j].Variant
+	})
+
+	return result, nil
+}
+
+// configuredExperiment 实验是否在配置中（无论是否启用）
+func configuredExperiment(name string) bool {
+	for _, exp := range config.AppConfig.Experiments {
+		if exp.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// wilsonInterval 计算二项比例的Wilson置信区间
+func wilsonInterval(successes, n int64, z float64) [2]float64 {
+	if n == 0 {
+		return [2]float64{}
+	}
+
+	p := float64(successes) / float64(n)
+	nf := float64(n)
+	denominator := 1 + z*z/nf
+	center := (p + z*z/(2*nf)) / denominator
+	margin := z * math.Sqrt(p*(1-p)/nf+z*z/(4*nf*nf)) / denominator
+
+	return [2]float64{math.Max(0, center-margin), math.Min(1, center+margin)}
+}
+
+// meanInterval 根据样本数、和与平方和计算均值的正态近似置信区间
+func meanInterval(n int64, sum, sqSum, z float64) [2]float64 {
+	if n == 0 {
+		return [2]float64{}
+	}
+
+	nf := float64(n)
+	mean := sum / nf
+	if n == 1 {
+		return [2]float64{mean, mean}
+	}
+
+	variance := math.Max(0, (sqSum-nf*mean*mean)/(nf-1))
+	margin := z * math.Sqrt(variance/nf)
+
+	return [2]float64{mean - margin, mean + margin}
+}
@@ -0,0 +1,14 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetReportRequiresName(t *testing.T) {
+	for _, name := range []string{"", "  "} {
+		if _, err := NewExperimentService().GetReport(name); !errors.Is(err, ErrExperimentNameRequired) {
+			t.Errorf("GetReport(%q) error = %v, want ErrExperimentNameRequired", name, err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+)
+
+// ExplorationService 基于Thompson采样的探索层，为曝光不足的新项目预留推荐位
+type ExplorationService struct {
+	projectRepo *repositories.ProjectRepository
+}
+
+func NewExplorationService() *ExplorationService {
+	return &ExplorationService{
+		projectRepo: repositories.NewProjectRepository(),
+	}
+}
+
+// RecordImpressions 记录浏览流中返回的项目曝光
+func (s *ExplorationService) RecordImpressions(projects []models.Project) error {
+	ids := make([]int64, 0, len(projects))
+	for _, project := range projects {
+		ids = append(ids, project.ID)
+	}
+	return s.projectRepo.IncrementImpressions(ids)
+}
+
+// RecordOutcome 根据交互结果更新项目的后验分布
+func (s *ExplorationService) RecordOutcome(projectID int64, interactionType string) error {
+	switch interactionType {
+	case "like", "super_like":
+		return s.projectRepo.RecordBanditOutcome(projectID, true)
+	case "dislike", "skip":
+		return s.projectRepo.RecordBanditOutcome(projectID, false)
+	}
+	return nil
+}
+
+// ApplyExploration 用探索项目替换排序结果中的部分推荐位，返回最终的前limit个结果
+func (s *ExplorationService) ApplyExploration(ranked []RecommendationScore, limit int, rate float64, maxImpressions int) ([]RecommendationScore, error) {
+	if rate <= 0 || len(ranked) <= limit {
+		return truncateScores(ranked, limit), nil
+	}
+
+	ids := make([]int64, 0, len(ranked))
+	for _, rec := range ranked {
+		ids = append(ids, rec.ProjectID)
+	}
+
+	stats, err := s.projectRepo.GetBanditStats(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return mixExploration(ranked, stats, limit, rate, maxImpressions, rand.Float64), nil
+}
+
+// mixExploration 从曝光不足的候选中按Thompson采样挑选探索项目，并均匀穿插到利用结果中
+func mixExploration(
+	ranked []RecommendationScore,
+	stats map[int64]models.ProjectBanditStats,
+	limit int,
+	rate float64,
+	maxImpressions int,
+	uniform func() float64,
+) []RecommendationScore {
+	slots := int(math.Round(float64(limit) * rate))
+	if slots <= 0 {
+		return truncateScores(ranked, limit)
+	}
+
+	// 排序结果中本就会入选的项目不参与探索
+	type sampled struct {
+		rec   RecommendationScore
+		theta float64
+	}
+	var pool []sampled
+	for _, rec := range ranked[min(limit, len(ranked)):] {
+		stat := stats[rec.ProjectID]
+		if stat.Impressions >= maxImpressions {
+			continue
+		}
+		theta := sampleBeta(float64(1+stat.Successes), float64(1+stat.Failures), uniform)
+		pool = append(pool, sampled{rec: rec, theta: theta})
+	}
+
+	if len(pool) == 0 {
+		return truncateScores(ranked, limit)
+	}
+
+	sort.Slice(pool, func(i, j int) bool {
+		return pool[i].theta > pool[j].theta
+	})
+	slots = min(slots, len(pool))
+
+	exploit := truncateScores(ranked, limit-slots)
+	step := float64(limit) / float64(slots)
+
+	result := make([]RecommendationScore, 0, limit)
+	next := 0
+	for i := 0; i < limit && (len(exploit) > 0 || next < slots); i++ {
+		// 探索项目放在每段的末尾位置
+		if next < slots && i == int(step*float64(next+1))-1 {
+			rec := pool[next].rec
+			rec.Reason = "探索新项目"
+			result = append(result, rec)
+			next++
+			continue
+		}
+		if len(exploit) > 0 {
+			result = append(result, exploit[0])
+			exploit = exploit[1:]
+		}
+	}
+
+	return result
+}
+
+func truncateScores(scores []RecommendationScore, limit int) []RecommendationScore {
+	if len(scores) > limit {
+		return scores[:limit]
+	}
+	return scores
+}
+
+// sampleBeta 通过两个Gamma变量采样Beta(a, b)
+func sampleBeta(a, b float64, uniform func() float64) float64 {
+	x := sampleGamma(a, uniform)
+	y := sampleGamma(b, uniform)
+	if x+y == 0 {
+		return 0
+	}
+	return x / (x + y)
+}
+
+// sampleGamma 使用Marsaglia-Tsang方法采样Gamma(shape, 1)，shape >= 1
+func sampleGamma(shape float64, uniform func() float64) float64 {
+	d := shape - 1.0/3.0
+	c := 1.0 / math.Sqrt(9*d)
+	for {
+		var x, v float64
+		for v <= 0 {
+			x = sampleNormal(uniform)
+			v = 1 + c*x
+		}
+		v = v * v * v
+		u := uniform()
+		if u < 1-0.0331*x*x*x*x {
+			return d * v
+		}
+		if u > 0 && math.Log(u) < 0.5*x*x+d*(1-v+math.Log(v)) {
+			return d * v
+		}
+	}
+}
+
+// sampleNormal 使用Box-Muller变换采样标准正态分布
+func sampleNormal(uniform func() float64) float64 {
+	u1 := uniform()
+	for u1 == 0 {
+		u1 = uniform()
+	}
+	u2 := uniform()
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+}
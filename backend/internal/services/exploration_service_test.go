@@ -0,0 +1,120 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"devswipe-backend/internal/models"
+)
+
+// fixedUniform 返回固定值的均匀分布，使采样结果只由后验参数决定
+func fixedUniform() float64 {
+	return 0.5
+}
+
+func rankedScores(n int) []RecommendationScore {
+	ranked := make([]RecommendationScore, 0, n)
+	for i := 1; i <= n; i++ {
+		ranked = append(ranked, RecommendationScore{ProjectID: int64(i), Score: 1 - float64(i)/100})
+	}
+	return ranked
+}
+
+func TestMixExplorationReservesSlots(t *testing.T) {
+	ranked := rankedScores(20)
+
+	got := mixExploration(ranked, nil, 10, 0.2, 100, fixedUniform)
+	if len(got) != 10 {
+		t.Fatalf("len(result) = %d, want 10", len(got))
+	}
+
+	explored := 0
+	for _, rec := range got {
+		if rec.Reason == "探索新项目" {
+			explored++
+			if rec.ProjectID <= 10 {
+				t.Errorf("project %d is already in the top results and should not be explored", rec.ProjectID)
+			}
+		}
+	}
+	if explored != 2 {
+		t.Fatalf("explored = %d, want 2", explored)
+	}
+}
+
+func TestMixExplorationPlacement(t *testing.T) {
+	ranked := rankedScores(12)
+	// 成功次数越多的候选采样值越大，应排在第一个探索位
+	stats := map[int64]models.ProjectBanditStats{
+		11: {ProjectID: 11, Impressions: 5, Successes: 0, Failures: 4},
+		12: {ProjectID: 12, Impressions: 5, Successes: 4, Failures: 0},
+	}
+
+	got := projectIDs(mixExploration(ranked, stats, 10, 0.2, 100, fixedUniform))
+	// 10个位置、2个探索位：探索项目放在第5和第10位，其余按原顺序
+	want := []int64{1, 2, 3, 4, 12, 5, 6, 7, 8, 11}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("mixExploration() = %v, want %v", got, want)
+	}
+}
+
+func TestMixExplorationImpressionCap(t *testing.T) {
+	ranked := rankedScores(12)
+	stats := map[int64]models.ProjectBanditStats{
+		11: {ProjectID: 11, Impressions: 50},
+		12: {ProjectID: 12, Impressions: 49},
+	}
+
+	got := mixExploration(ranked, stats, 10, 0.2, 50, fixedUniform)
+	explored := []int64{}
+	for _, rec := range got {
+		if rec.Reason == "探索新项目" {
+			explored = append(explored, rec.ProjectID)
+		}
+	}
+	if !reflect.DeepEqual(explored, []int64{12}) {
+		t.Fatalf("explored = %v, want [12]", explored)
+	}
+	if len(got) != 10 {
+		t.Fatalf("len(result) = %d, want 10", len(got))
+	}
+
+	// 所有候选都达到曝光上限时不做探索
+	stats[12] = models.ProjectBanditStats{ProjectID: 12, Impressions: 50}
+	got = mixExploration(ranked, stats, 10, 0.2, 50, fixedUniform)
+	if !reflect.DeepEqual(projectIDs(got), projectIDs(ranked[:10])) {
+		t.Fatalf("mixExploration() = %v, want top 10 unchanged", projectIDs(got))
+	}
+}
+
+func TestMixExplorationZeroRate(t *testing.T) {
+	ranked := rankedScores(12)
+
+	got := projectIDs(mixExploration(ranked, nil, 10, 0, 100, fixedUniform))
+	if !reflect.DeepEqual(got, projectIDs(ranked[:10])) {
+		t.Fatalf("mixExploration() = %v, want top 10 unchanged", got)
+	}
+}
+
+func TestSampleBetaRange(t *testing.T) {
+	values := []float64{0.1, 0.9, 0.3, 0.7, 0.5, 0.2, 0.8}
+	next := 0
+	uniform := func() float64 {
+		v := values[next%len(values)]
+		next++
+		return v
+	}
+
+	for _, params := range [][2]float64{{1, 1}, {5, 1}, {1, 5}, {20, 30}} {
+		theta := sampleBeta(params[0], params[1], uniform)
+		if math.IsNaN(theta) || theta < 0 || theta > 1 {
+			t.Errorf("sampleBeta(%v, %v) = %v, want value in [0, 1]", params[0], params[1], theta)
+		}
+	}
+
+	// 相同的随机数下，成功次数更多的后验采样值更大
+	if low, high := sampleBeta(1, 5, fixedUniform), sampleBeta(5, 1, fixedUniform); low >= high {
+		t.Errorf("sampleBeta(1, 5) = %v should be below sampleBeta(5, 1) = %v", low, high)
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/pkg/cache"
+)
+
+const (
+	// followingFeedCacheSize 只缓存第一页的前N条，翻页使用游标直接查询
+	followingFeedCacheSize = 50
+	followingFeedCacheTTL  = 10 * time.Minute
+	// followingInvalidateBatch 作者发布内容时分批删除关注者的缓存
+	followingInvalidateBatch = 500
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type FollowingFeedService struct {
+	projectRepo *repositories.ProjectRepository
+	userRepo    *repositories.UserRepository
+	cache       *cache.CacheManager
+}
+
+func NewFollowingFeedService() *FollowingFeedService {
+	return &FollowingFeedService{
+		projectRepo: repositories.NewProjectRepository(),
+		userRepo:    repositories.NewUserRepository(),
+		cache:       cache.NewCacheManager(),
+	}
+}
+
+// FollowingFeedItem 关注动态流中的一个条目：新项目或项目更新
+type FollowingFeedItem struct {
+	Type      string                `json:"type"` // project, update
+	CreatedAt time.Time             `json:"created_at"`
+	Project   *models.Project       `json:"project"`
+	Update    *models.ProjectUpdate `json:"update,omitempty"`
+}
+
+// FollowingFeedPage 关注动态流的一页
+type FollowingFeedPage struct {
+	Items      []FollowingFeedItem `json:"items"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+	HasMore    bool                `json:"has_more"`
+}
+
+// GetFeed 按时间倒序获取关注的作者发布的新项目和项目更新，cursor 为空时返回第一页
+func (s *FollowingFeedService) GetFeed(userID int64, cursor string, limit int) (*FollowingFeedPage, error) {
+	ctx := context.Background()
+	cacheKey := followingFeedCacheKey(userID)
+
+	if cursor == "" && limit <= followingFeedCacheSize {
+		var cached []FollowingFeedItem
+		if err := s.cache.Get(ctx, cacheKey, &cached); err == nil {
+			return buildFollowingFeedPage(cached, limit), nil
+		}
+
+		items, err := s.loadItems(userID, nil, followingFeedCacheSize+1)
+		if err != nil {
+			return nil, err
+		}
+		s.cache.Set(ctx, cacheKey, items, followingFeedCacheTTL)
+		return buildFollowingFeedPage(items, limit), nil
+	}
+
+	var feedCursor *repositories.FeedCursor
+	if cursor != "" {
+		decoded, err := decodeFeedCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		feedCursor = decoded
+	}
+
+	items, err := s.loadItems(userID, feedCursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	return buildFollowingFeedPage(items, limit), nil
+}
+
+// InvalidateFollowers 作者发布新项目或更新项目后，清除其关注者的动态流缓存
+func (s *FollowingFeedService) InvalidateFollowers(creatorID int64) error {
+	followerIDs, err := s.userRepo.GetFollowerIDs(creatorID)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	for start := 0; start < len(followerIDs); start += followingInvalidateBatch {
+		end := min(start+followingInvalidateBatch, len(followerIDs))
+		keys := make([]string, 0, end-start)
+		for _, id := range followerIDs[start:end] {
+			keys = append(keys, followingFeedCacheKey(id))
+		}
+		if err := s.cache.DeleteKeys(ctx, keys...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Invalidate 清除单个用户的动态流缓存，关注关系变化时调用
+func (s *FollowingFeedService) Invalidate(userID int64) error {
+	return s.cache.Delete(context.Background(), followingFeedCacheKey(userID))
+}
+
+// loadItems 分别取出新项目和更新各n条，合并后按时间倒序保留前n条
+func (s *FollowingFeedService) loadItems(userID int64, cursor *repositories.FeedCursor, n int) ([]FollowingFeedItem, error) {
+	projects, err := s.projectRepo.GetFollowingProjects(userID, cursor, n)
+	if err != nil {
+		return nil, err
+	}
+
+	updates, err := s.projectRepo.GetFollowingUpdates(userID, cursor, n)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]FollowingFeedItem, 0, len(projects)+len(updates))
+	for i := range projects {
+		items = append(items, FollowingFeedItem{
+			Type:      repositories.FeedItemProject,
+			CreatedAt: projects[i].CreatedAt,
+			Project:   &projects[i],
+		})
+	}
+	for i := range updates {
+		items = append(items, FollowingFeedItem{
+			Type:      repositories.FeedItemUpdate,
+			CreatedAt: updates[i].CreatedAt,
+			Project:   &updates[i].Project,
+			Update:    &updates[i],
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		if a.Type != b.Type {
+			return a.Type > b.Type
+		}
+		return a.itemID() > b.itemID()
+	})
+
+	if len(items) > n {
+		items = items[:n]
+	}
+	return items, nil
+}
+
+func (item FollowingFeedItem) itemID() int64 {
+	if item.Update != nil {
+		return item.Update.ID
+	}
+	return item.Project.ID
+}
+
+func buildFollowingFeedPage(items []FollowingFeedItem, limit int) *FollowingFeedPage {
+	page := &FollowingFeedPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.HasMore = true
+	}
+
+	if page.HasMore {
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = encodeFeedCursor(&repositories.FeedCursor{
+			CreatedAt: last.CreatedAt,
+			Kind:      last.Type,
+			ID:        last.itemID(),
+		})
+	}
+	return page
+}
+
+// encodeFeedCursor 游标格式为 base64("unixnano:kind:id")
+func encodeFeedCursor(cursor *repositories.FeedCursor) string {
+	raw := fmt.Sprintf("%d:%s:%d", cursor.CreatedAt.UnixNano(), cursor.Kind, cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeFeedCursor(cursor string) (*repositories.FeedCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCursor
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if parts[1] != repositories.FeedItemProject && parts[1] != repositories.FeedItemUpdate {
+		return nil, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &repositories.FeedCursor{
+		CreatedAt: time.Unix(0, nanos),
+		Kind:      parts[1],
+		ID:        id,
+	}, nil
+}
+
+func followingFeedCacheKey(userID int64) string {
+	return fmt.Sprintf("following_feed:%d", userID)
+}
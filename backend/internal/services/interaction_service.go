@@ -5,19 +5,30 @@ import (
 	"devswipe-backend/internal/repositories"
 	"devswipe-backend/pkg/database"
 	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
 
+	"github.com/gin-gonic/gin/binding"
 	"gorm.io/gorm"
 )
 
 type InteractionService struct {
-	interactionRepo *repositories.InteractionRepository
-	projectRepo     *repositories.ProjectRepository
+	interactionRepo    *repositories.InteractionRepository
+	projectRepo        *repositories.ProjectRepository
+	preferenceService  *PreferenceService
+	explorationService *ExplorationService
+	experimentService  *ExperimentService
 }
 
 func NewInteractionService() *InteractionService {
 	return &InteractionService{
-		interactionRepo: repositories.NewInteractionRepository(),
-		projectRepo:     repositories.NewProjectRepository(),
+		interactionRepo:    repositories.NewInteractionRepository(),
+		projectRepo:        repositories.NewProjectRepository(),
+		preferenceService:  NewPreferenceService(),
+		explorationService: NewExplorationService(),
+		experimentService:  NewExperimentService(),
 	}
 }
 
@@ -36,75 +47,129 @@ type CommentRequest struct {
 }
 
 func (s *InteractionService) ProcessInteraction(userID int64, req *InteractionRequest) error {
-	// 检查项目是否存在
-	_, err := s.projectRepo.GetByID(req.ProjectID)
+	var interaction *models.UserInteraction
+	var project *models.Project
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		interaction, project, err = s.applyInteraction(tx, userID, req, nil)
+		return err
+	})
 	if err != nil {
-		return errors.New("project not found")
+		return err
+	}
+
+	s.afterInteraction(userID, project, interaction)
+	return nil
+}
+
+// applyInteraction 在事务中写入一次交互并更新项目统计
+func (s *InteractionService) applyInteraction(tx *gorm.DB, userID int64, req *InteractionRequest, configure func(*models.UserInteraction)) (*models.UserInteraction, *models.Project, error) {
+	// 检查项目是否存在
+	var project models.Project
+	if err := tx.Preload("Tags").First(&project, req.ProjectID).Error; err != nil {
+		return nil, nil, errors.New("project not found")
 	}
 
 	// 检查是否已经交互过
 	var existingInteraction models.UserInteraction
-	err = database.DB.Where("user_id = ? AND project_id = ? AND interaction_type = ?",
+	err := tx.Where("user_id = ? AND project_id = ? AND interaction_type = ?",
 		userID, req.ProjectID, req.Type).First(&existingInteraction).Error
 	if err == nil {
-		return errors.New("already interacted with this project")
-	}
-
-	// 使用事务处理交互
-	return database.DB.Transaction(func(tx *gorm.DB) error {
-		// 创建交互记录
-		interaction := &models.UserInteraction{
-			UserID:             userID,
-			ProjectID:          req.ProjectID,
-			InteractionType:    req.Type,
-			StructuredFeedback: req.StructuredFeedback,
-			ViewDuration:       req.ViewDuration,
-			SessionID:          req.SessionID,
-		}
+		return nil, nil, errors.New("already interacted with this project")
+	}
 
-		if err := tx.Create(interaction).Error; err != nil {
-			return err
-		}
+	// 创建交互记录
+	interaction := &models.UserInteraction{
+		UserID:             userID,
+		ProjectID:          req.ProjectID,
+		InteractionType:    req.Type,
+		StructuredFeedback: req.StructuredFeedback,
+		ViewDuration:       req.ViewDuration,
+		SessionID:          req.SessionID,
+	}
 
-		// 更新项目统计
-		var updateField string
-		switch req.Type {
-		case "like":
-			updateField = "like_count = like_count + 1"
-		case "dislike":
-			updateField = "dislike_count = dislike_count + 1"
-		case "super_like":
-			updateField = "super_like_count = super_like_count + 1"
-		case "skip":
-			updateField = "skip_count = skip_count + 1"
-		}
+	// 按交互前最近一次曝光的分组标记交互，查询失败时不做归因
+	assignment, err := s.experimentService.ExposedAssignment(userID, req.SessionID)
+	if err != nil {
+		log.Printf("Failed to look up experiment exposure for user %d: %v", userID, err)
+	}
+	if assignment != nil {
+		interaction.Experiment = assignment.Experiment
+		interaction.Variant = assignment.Variant
+	}
 
-		if updateField != "" {
-			if err := tx.Model(&models.Project{}).
-				Where("id = ?", req.ProjectID).
-				Update(updateField, gorm.Expr(updateField)).Error; err != nil {
-				return err
-			}
+	if configure != nil {
+		configure(interaction)
+	}
+
+	if err := tx.Create(interaction).Error; err != nil {
+		return nil, nil, err
+	}
+
+	// 更新项目统计
+	if column := interactionCounterColumn(req.Type); column != "" {
+		if err := tx.Model(&models.Project{}).
+			Where("id = ?", req.ProjectID).
+			Update(column, gorm.Expr(column+" + 1")).Error; err != nil {
+			return nil, nil, err
 		}
+	}
 
-		return nil
-	})
+	return interaction, &project, nil
+}
+
+// interactionCounterColumn 交互类型在项目表中对应的计数字段，bookmark 等没有计数的类型返回空
+func interactionCounterColumn(interactionType string) string {
+	switch interactionType {
+	case "like":
+		return "like_count"
+	case "dislike":
+		return "dislike_count"
+	case "super_like":
+		return "super_like_count"
+	case "skip":
+		return "skip_count"
+	}
+	return ""
+}
+
+// afterInteraction 事务提交后更新画像和探索统计，失败不影响交互本身
+func (s *InteractionService) afterInteraction(userID int64, project *models.Project, interaction *models.UserInteraction) {
+	// 增量更新用户标签画像
+	if err := s.preferenceService.RecordInteraction(userID, project.Tags, interaction.InteractionType, interaction.StructuredFeedback); err != nil {
+		log.Printf("Failed to update tag affinity for user %d: %v", userID, err)
+	}
+
+	// 更新探索层的后验分布
+	if err := s.explorationService.RecordOutcome(project.ID, interaction.InteractionType); err != nil {
+		log.Printf("Failed to record exploration outcome for project %d: %v", project.ID, err)
+	}
 }
 
 func (s *InteractionService) AddComment(userID int64, req *CommentRequest) (*models.Comment, error) {
 	// 检查项目是否存在
-	_, err := s.projectRepo.GetByID(req.ProjectID)
+	project, err := s.projectRepo.GetByID(req.ProjectID)
 	if err != nil {
 		return nil, errors.New("project not found")
 	}
 
+	// 与项目作者互相拉黑时不能评论
+	blockService := NewBlockService()
+	if err := blockService.CheckInteraction(userID, project.UserID); err != nil {
+		return nil, err
+	}
+
 	// 检查是否是回复评论
 	if req.ParentID != nil {
 		var parentComment models.Comment
 		err = database.DB.First(&parentComment, *req.ParentID).Error
-		if err != nil {
+		if err != nil || parentComment.ProjectID != req.ProjectID {
 			return nil, errors.New("parent comment not found")
 		}
+		if err := blockService.CheckInteraction(userID, parentComment.UserID); err != nil {
+			return nil, err
+		}
 	}
 
 	// 使用事务创建评论
@@ -142,9 +207,21 @@ func (s *InteractionService) AddComment(userID int64, req *CommentRequest) (*mod
 	return &comment, nil
 }
 
-func (s *InteractionService) GetProjectComments(projectID int64, limit, offset int) ([]models.Comment, error) {
+// GetProjectComments 获取项目评论及回复，viewerID 拉黑、静音的用户和拉黑了 viewerID 的用户的评论不返回
+func (s *InteractionService) GetProjectComments(viewerID, projectID int64, limit, offset int) ([]models.Comment, error) {
+	hiddenIDs, err := NewBlockService().HiddenUserIDs(viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := database.DB.Preload("User")
+	if len(hiddenIDs) > 0 {
+		query = query.Preload("Replies", "user_id NOT IN ?", hiddenIDs).
+			Where("user_id NOT IN ?", hiddenIDs)
+	}
+
 	var comments []models.Comment
-	err := database.DB.Preload("User").Preload("Replies.User").
+	err = query.Preload("Replies.User").
 		Where("project_id = ? AND parent_id IS NULL", projectID).
 		Order("created_at DESC").
 		Limit(limit).Offset(offset).
@@ -166,3 +243,126 @@ func (s *InteractionService) GetUserInteractions(userID int64, interactionType s
 		Find(&interactions).Error
 	return interactions, err
 }
+
+// 批量交互中单个条目的处理结果
+const (
+	BatchItemApplied   = "applied"
+	BatchItemDuplicate = "duplicate" // 幂等键已处理过，视为成功
+	BatchItemFailed    = "failed"
+)
+
+// maxBatchInteractions 单次批量上报的最大条数
+const maxBatchInteractions = 100
+
+type BatchInteractionItem struct {
+	InteractionRequest
+	IdempotencyKey  string    `json:"idempotency_key" binding:"required,max=64"`
+	ClientTimestamp time.Time `json:"client_timestamp" binding:"required"`
+}
+
+type BatchInteractionRequest struct {
+	Items []BatchInteractionItem `json:"items" binding:"required,min=1,max=100"`
+}
+
+type BatchInteractionResult struct {
+	Index          int    `json:"index"`
+	IdempotencyKey string `json:"idempotency_key"`
+	ProjectID      int64  `json:"project_id"`
+	Status         string `json:"status"`
+	InteractionID  int64  `json:"interaction_id,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// ProcessBatch 按客户端时间顺序在同一个事务中应用一批交互。
+// 每个条目使用独立的保存点，单条失败不影响其他条目；结果按请求中的顺序返回。
+func (s *InteractionService) ProcessBatch(userID int64, req *BatchInteractionRequest) ([]BatchInteractionResult, error) {
+	if len(req.Items) > maxBatchInteractions {
+		return nil, fmt.Errorf("too many interactions, at most %d per batch", maxBatchInteractions)
+	}
+
+	results := make([]BatchInteractionResult, len(req.Items))
+	for i, item := range req.Items {
+		results[i] = BatchInteractionResult{
+			Index:          i,
+			IdempotencyKey: item.IdempotencyKey,
+			ProjectID:      item.ProjectID,
+		}
+	}
+
+	// 网络抖动可能导致乱序到达，按客户端时间重新排序
+	order := make([]int, len(req.Items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return req.Items[order[a]].ClientTimestamp.Before(req.Items[order[b]].ClientTimestamp)
+	})
+
+	type appliedItem struct {
+		project     *models.Project
+		interaction *models.UserInteraction
+	}
+	var applied []appliedItem
+	seenKeys := make(map[string]int64)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, i := range order {
+			item := req.Items[i]
+			result := &results[i]
+
+			if err := binding.Validator.ValidateStruct(&item); err != nil {
+				result.Status = BatchItemFailed
+				result.Error = err.Error()
+				continue
+			}
+
+			// 同一批次内或之前的批次已处理过该幂等键
+			if id, ok := seenKeys[item.IdempotencyKey]; ok {
+				result.Status = BatchItemDuplicate
+				result.InteractionID = id
+				continue
+			}
+			var existing models.UserInteraction
+			err := tx.Where("user_id = ? AND idempotency_key = ?", userID, item.IdempotencyKey).First(&existing).Error
+			if err == nil {
+				result.Status = BatchItemDuplicate
+				result.InteractionID = existing.ID
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			var interaction *models.UserInteraction
+			var project *models.Project
+			err = tx.Transaction(func(itemTx *gorm.DB) error {
+				var err error
+				interaction, project, err = s.applyInteraction(itemTx, userID, &item.InteractionRequest, func(interaction *models.UserInteraction) {
+					interaction.IdempotencyKey = &item.IdempotencyKey
+					interaction.ClientCreatedAt = &item.ClientTimestamp
+				})
+				return err
+			})
+			if err != nil {
+				result.Status = BatchItemFailed
+				result.Error = err.Error()
+				continue
+			}
+
+			result.Status = BatchItemApplied
+			result.InteractionID = interaction.ID
+			seenKeys[item.IdempotencyKey] = interaction.ID
+			applied = append(applied, appliedItem{project: project, interaction: interaction})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range applied {
+		s.afterInteraction(userID, item.project, item.interaction)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"devswipe-backend/internal/config"
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/pkg/cache"
+	"devswipe-backend/pkg/mailer"
+)
+
+// 审计日志中的登录防护事件
+const (
+	AuditLoginLockout   = "login_lockout"
+	AuditLoginIPLockout = "login_ip_lockout"
+	AuditLoginUnlock    = "login_unlock"
+)
+
+var ErrInvalidUnlockToken = errors.New("invalid or expired unlock token")
+
+// LoginLockedError 账户或IP处于退避或锁定状态
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %d seconds", int(e.RetryAfter.Round(time.Second).Seconds()))
+}
+
+// LoginGuard 记录按账户和按IP的登录失败次数：超过免费次数后指数退避，
+// 达到阈值后临时锁定并写入审计日志。计数保存在Redis中，多实例共享。
+type LoginGuard struct {
+	auditRepo *repositories.AuditRepository
+	cache     *cache.CacheManager
+	mailer    mailer.Mailer
+}
+
+func NewLoginGuard() *LoginGuard {
+	return &LoginGuard{
+		auditRepo: repositories.NewAuditRepository(),
+		cache:     cache.NewCacheManager(),
+		mailer:    mailer.NewMailer(),
+	}
+}
+
+// Check 登录前检查账户和IP是否被锁定
+func (g *LoginGuard) Check(email, ip string) error {
+	ctx := context.Background()
+	for _, key := range []string{loginLockKey("account", normalizeEmail(email)), loginLockKey("ip", ip)} {
+		ttl, err := g.cache.TTL(ctx, key)
+		if err != nil {
+			// Redis不可用时不阻止登录
+			log.Printf("Login guard check failed: %v", err)
+			return nil
+		}
+		if ttl > 0 {
+			return &LoginLockedError{RetryAfter: ttl}
+		}
+	}
+	return nil
+}
+
+// RecordFailure 记录一次失败登录，user 为空表示邮箱不存在（同样计数，避免泄露账户是否存在）
+func (g *LoginGuard) RecordFailure(email, ip string, user *models.User) {
+	cfg := config.AppConfig.LoginGuard
+	ctx := context.Background()
+	window := time.Duration(cfg.FailureWindow) * time.Minute
+	account := normalizeEmail(email)
+
+	failures, err := g.cache.Increment(ctx, loginFailuresKey("account", account), window)
+	if err != nil {
+		log.Printf("Failed to record login failure: %v", err)
+		return
+	}
+
+	if cfg.LockoutThreshold > 0 && failures >= int64(cfg.LockoutThreshold) {
+		g.lockAccount(ctx, account, ip, user, failures)
+	} else if delay := backoffDelay(failures); delay > 0 {
+		g.cache.Set(ctx, loginLockKey("account", account), time.Now().Unix(), delay)
+	}
+
+	ipFailures, err := g.cache.Increment(ctx, loginFailuresKey("ip", ip), window)
+	if err != nil {
+		log.Printf("Failed to record login failure: %v", err)
+		return
+	}
+	if cfg.IPLockoutThreshold > 0 && ipFailures == int64(cfg.IPLockoutThreshold) {
+		duration := time.Duration(cfg.LockoutDuration) * time.Minute
+		g.cache.Set(ctx, loginLockKey("ip", ip), time.Now().Unix(), duration)
+		recordAudit(g.auditRepo, nil, AuditLoginIPLockout, ip, fmt.Sprintf("failures=%d locked_for=%s", ipFailures, duration))
+	}
+}
+
+// RecordSuccess 登录成功后清除账户的失败计数，IP的计数保留到窗口结束
+func (g *LoginGuard) RecordSuccess(email string) {
+	ctx := context.Background()
+	account := normalizeEmail(email)
+	if err := g.cache.DeleteKeys(ctx,
+		loginFailuresKey("account", account),
+		loginLockKey("account", account),
+		loginUnlockKey(account),
+	); err != nil {
+		log.Printf("Failed to reset login failures: %v", err)
+	}
+}
+
+// Unlock 使用锁定时发放的解锁令牌解除账户锁定
+func (g *LoginGuard) Unlock(user *models.User, token, ip string) error {
+	ctx := context.Background()
+	account := normalizeEmail(user.Email)
+
+	var expected string
+	if err := g.cache.Get(ctx, loginUnlockKey(account), &expected); err != nil {
+		return ErrInvalidUnlockToken
+	}
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(hashSecureToken(token))) != 1 {
+		return ErrInvalidUnlockToken
+	}
+
+	g.RecordSuccess(user.Email)
+	recordAudit(g.auditRepo, &user.ID, AuditLoginUnlock, ip, "unlocked with token")
+	return nil
+}
+
+// lockAccount 锁定账户。已存在的账户每次新加锁（包括上一次锁定过期后在失败窗口内再次锁定）
+// 都写入审计日志并发放一次性解锁令牌；已处于锁定中时只延长锁定
+func (g *LoginGuard) lockAccount(ctx context.Context, account, ip string, user *models.User, failures int64) {
+	duration := time.Duration(config.AppConfig.LoginGuard.LockoutDuration) * time.Minute
+	lockKey := loginLockKey("account", account)
+
+	locked, err := g.cache.SetNX(ctx, lockKey, time.Now().Unix(), duration)
+	if err != nil {
+		log.Printf("Failed to lock account: %v", err)
+		return
+	}
+	if !locked {
+		g.cache.Set(ctx, lockKey, time.Now().Unix(), duration)
+		return
+	}
+	if user == nil {
+		return
+	}
+
+	recordAudit(g.auditRepo, &user.ID, AuditLoginLockout, ip, fmt.Sprintf("failures=%d locked_for=%s", failures, duration))
+
+	token, err := generateSecureToken()
+	if err != nil {
+		log.Printf("Failed to generate unlock token: %v", err)
+		return
+	}
+	if err := g.cache.Set(ctx, loginUnlockKey(account), hashSecureToken(token), duration); err != nil {
+		log.Printf("Failed to store unlock token: %v", err)
+		return
+	}
+	g.sendUnlockEmail(user, token, duration)
+}
+
+// backoffDelay 超过免费次数后，每次失败的等待时间翻倍
+func backoffDelay(failures int64) time.Duration {
+	cfg := config.AppConfig.LoginGuard
+	excess := failures - int64(cfg.FreeAttempts)
+	if excess <= 0 || cfg.BaseDelay <= 0 {
+		return 0
+	}
+
+	maxDelay := time.Duration(cfg.MaxDelay) * time.Second
+	delay := time.Duration(cfg.BaseDelay) * time.Second
+	for i := int64(1); i < excess && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+// sendUnlockEmail 通知用户账户已被锁定，并附上解锁令牌
+func (g *LoginGuard) sendUnlockEmail(user *models.User, token string, duration time.Duration) {
+	err := g.mailer.Send(context.Background(), mailer.Message{
+		To:      user.Email,
+		Subject: "你的 DevSwipe 账户已被临时锁定",
+		Body: fmt.Sprintf("你好 %s，\n\n由于多次登录失败，你的账户已被临时锁定 %d 分钟。\n如果是你本人操作，可以使用下面的解锁令牌立即解除锁定：\n%s\n\n如果不是你本人操作，建议尽快重置密码。\n",
+			user.Username, int(duration.Minutes()), token),
+	})
+	if err != nil {
+		log.Printf("Failed to send unlock email to user %d: %v", user.ID, err)
+	}
+}
+
+// generateSecureToken 生成随机令牌（32字节，十六进制编码）
+func generateSecureToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashSecureToken 令牌只以SHA-256哈希的形式保存
+func hashSecureToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func loginFailuresKey(scope, id string) string {
+	return fmt.Sprintf("login_failures:%s:%s", scope, id)
+}
+
+func loginLockKey(scope, id string) string {
+	return fmt.Sprintf("login_lock:%s:%s", scope, id)
+}
+
+func loginUnlockKey(account string) string {
+	return fmt.Sprintf("login_unlock:%s", account)
+}
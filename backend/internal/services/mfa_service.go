@@ -0,0 +1,373 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"devswipe-backend/internal/config"
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/pkg/cache"
+	"devswipe-backend/pkg/totp"
+)
+
+// 审计日志中的两步验证事件
+const (
+	AuditMFAEnable          = "mfa_enable"
+	AuditMFADisable         = "mfa_disable"
+	AuditMFARecoveryUsed    = "mfa_recovery_code_used"
+	AuditMFARecoveryRenewed = "mfa_recovery_codes_regenerated"
+	AuditMFAReset           = "mfa_admin_reset"
+)
+
+const (
+	// mfaRecoveryCodeCount 每次生成的恢复码数量
+	mfaRecoveryCodeCount = 10
+	// mfaMaxChallengeAttempts 每个MFA令牌允许的验证码错误次数
+	mfaMaxChallengeAttempts = 5
+	// mfaClockSkew 允许前后一个时间步的时钟偏差
+	mfaClockSkew = 1
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFANotEnrolled    = errors.New("start two-factor enrolment first")
+	ErrInvalidMFACode    = errors.New("invalid two-factor code")
+	ErrInvalidMFAToken   = errors.New("invalid or expired mfa token")
+)
+
+type MFAService struct {
+	mfaRepo    *repositories.MFARepository
+	userRepo   *repositories.UserRepository
+	auditRepo  *repositories.AuditRepository
+	loginGuard *LoginGuard
+	cache      *cache.CacheManager
+}
+
+func NewMFAService() *MFAService {
+	return &MFAService{
+		mfaRepo:    repositories.NewMFARepository(),
+		userRepo:   repositories.NewUserRepository(),
+		auditRepo:  repositories.NewAuditRepository(),
+		loginGuard: NewLoginGuard(),
+		cache:      cache.NewCacheManager(),
+	}
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"` // 认证器中的6位验证码，或一个恢复码
+}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// mfaChallenge 密码校验通过后保存在Redis中的登录上下文
+type mfaChallenge struct {
+	UserID int64 `json:"user_id"`
+}
+
+// Status 获取用户的两步验证状态
+func (s *MFAService) Status(userID int64) (*MFAStatus, error) {
+	mfa, err := s.mfaRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil || mfa.ConfirmedAt == nil {
+		return &MFAStatus{}, nil
+	}
+
+	remaining, err := s.mfaRepo.CountUnusedRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAStatus{
+		Enabled:                true,
+		ConfirmedAt:            mfa.ConfirmedAt,
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+// Enroll 生成新的密钥，确认之前不生效。重复调用会替换未确认的密钥
+func (s *MFAService) Enroll(userID int64) (*MFAEnrollResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mfa, err := s.mfaRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa != nil && mfa.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		mfa = &models.UserMFA{UserID: userID}
+	}
+	mfa.Secret = secret
+	mfa.LastUsedStep = 0
+	if err := s.mfaRepo.Save(mfa); err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(config.AppConfig.MFA.Issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm 使用认证器生成的验证码确认绑定，成功后启用两步验证并返回恢复码
+func (s *MFAService) Confirm(userID int64, code, ip string) ([]string, error) {
+	mfa, err := s.mfaRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if mfa.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(mfa.Secret, code, time.Now(), mfaClockSkew)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	now := time.Now()
+	mfa.ConfirmedAt = &now
+	mfa.LastUsedStep = step
+	if err := s.mfaRepo.Save(mfa); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.issueRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(s.auditRepo, &userID, AuditMFAEnable, ip, "")
+	return codes, nil
+}
+
+// Disable 关闭两步验证，需要提供有效的验证码或恢复码
+func (s *MFAService) Disable(userID int64, code, ip string) error {
+	if err := s.verifyCode(userID, code, ip); err != nil {
+		return err
+	}
+	if err := s.mfaRepo.Delete(userID); err != nil {
+		return err
+	}
+
+	recordAudit(s.auditRepo, &userID, AuditMFADisable, ip, "")
+	return nil
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧的恢复码全部失效
+func (s *MFAService) RegenerateRecoveryCodes(userID int64, code, ip string) ([]string, error) {
+	if err := s.verifyCode(userID, code, ip); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.issueRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(s.auditRepo, &userID, AuditMFARecoveryRenewed, ip, "")
+	return codes, nil
+}
+
+// Reset 管理员为丢失认证器和恢复码的用户关闭两步验证
+func (s *MFAService) Reset(actorID, userID int64, ip string) error {
+	if err := s.mfaRepo.Delete(userID); err != nil {
+		return err
+	}
+
+	recordAudit(s.auditRepo, &userID, AuditMFAReset, ip, fmt.Sprintf("reset by user %d", actorID))
+	return nil
+}
+
+// IsEnabled 判断用户是否已启用两步验证
+func (s *MFAService) IsEnabled(userID int64) (bool, error) {
+	mfa, err := s.mfaRepo.GetByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+	return mfa != nil && mfa.ConfirmedAt != nil, nil
+}
+
+// IssueChallenge 密码校验通过后发放短期MFA令牌，用于登录的第二步
+func (s *MFAService) IssueChallenge(userID int64) (string, error) {
+	token, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	ttl := time.Duration(config.AppConfig.MFA.ChallengeTTL) * time.Minute
+	if err := s.cache.Set(context.Background(), mfaChallengeKey(token), mfaChallenge{UserID: userID}, ttl); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// CompleteLogin 使用MFA令牌和验证码完成登录的第二步
+func (s *MFAService) CompleteLogin(req *MFALoginRequest, ip string) (*models.User, error) {
+	ctx := context.Background()
+	key := mfaChallengeKey(req.MFAToken)
+
+	var challenge mfaChallenge
+	if err := s.cache.Get(ctx, key, &challenge); err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.userRepo.GetByID(challenge.UserID)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	if err := s.loginGuard.Check(user.Email, ip); err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyCode(user.ID, req.Code, ip); err != nil {
+		if !errors.Is(err, ErrInvalidMFACode) {
+			return nil, err
+		}
+
+		// 同一个MFA令牌多次输错后作废，需要重新输入密码
+		ttl := time.Duration(config.AppConfig.MFA.ChallengeTTL) * time.Minute
+		attempts, incErr := s.cache.Increment(ctx, mfaAttemptsKey(req.MFAToken), ttl)
+		if incErr == nil && attempts >= mfaMaxChallengeAttempts {
+			s.cache.DeleteKeys(ctx, key, mfaAttemptsKey(req.MFAToken))
+		}
+		s.loginGuard.RecordFailure(user.Email, ip, user)
+		return nil, err
+	}
+
+	// MFA令牌只能使用一次
+	s.cache.DeleteKeys(ctx, key, mfaAttemptsKey(req.MFAToken))
+	s.loginGuard.RecordSuccess(user.Email)
+	return user, nil
+}
+
+// verifyCode 校验TOTP验证码，不匹配时再按恢复码校验
+func (s *MFAService) verifyCode(userID int64, code, ip string) error {
+	mfa, err := s.mfaRepo.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if mfa == nil || mfa.ConfirmedAt == nil {
+		return ErrMFANotEnabled
+	}
+
+	if step, ok := totp.Validate(mfa.Secret, code, time.Now(), mfaClockSkew); ok {
+		used, err := s.mfaRepo.UseStep(userID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	consumed, err := s.mfaRepo.ConsumeRecoveryCode(userID, hashSecureToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidMFACode
+	}
+
+	recordAudit(s.auditRepo, &userID, AuditMFARecoveryUsed, ip, "")
+	return nil
+}
+
+// issueRecoveryCodes 生成一组新的恢复码，明文只返回这一次
+func (s *MFAService) issueRecoveryCodes(userID int64) ([]string, error) {
+	codes := make([]string, 0, mfaRecoveryCodeCount)
+	hashes := make([]string, 0, mfaRecoveryCodeCount)
+	for i := 0; i < mfaRecoveryCodeCount; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		raw := hex.EncodeToString(buf)
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashSecureToken(raw))
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// loginResponse 密码或第三方登录成功后调用：启用了两步验证时返回MFA令牌，否则创建会话并签发JWT
+func loginResponse(user *models.User, ip, userAgent string) (*AuthResponse, error) {
+	mfaService := NewMFAService()
+	enabled, err := mfaService.IsEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if enabled {
+		mfaToken, err := mfaService.IssueChallenge(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &AuthResponse{
+			UserID:      user.ID,
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
+
+	token, err := NewSessionService().Create(user, ip, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthResponse{
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Token:    token,
+	}, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+func mfaChallengeKey(token string) string {
+	return fmt.Sprintf("mfa_challenge:%s", hashSecureToken(token))
+}
+
+func mfaAttemptsKey(token string) string {
+	return fmt.Sprintf("mfa_attempts:%s", hashSecureToken(token))
+}
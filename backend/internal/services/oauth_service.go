@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"time"
+
+	"devswipe-backend/internal/config"
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/pkg/cache"
+	"devswipe-backend/pkg/oauth"
+)
+
+// 审计日志中的第三方登录事件
+const (
+	AuditOAuthLink     = "oauth_link"
+	AuditOAuthUnlink   = "oauth_unlink"
+	AuditOAuthTakeover = "oauth_takeover"
+)
+
+const oauthStateTTL = 10 * time.Minute
+
+var (
+	ErrInvalidOAuthState       = errors.New("invalid or expired oauth state")
+	ErrIdentityLinkedElsewhere = errors.New("this account is already linked to another user")
+	ErrOAuthEmailRequired      = errors.New("a verified email address is required to sign up")
+	ErrIdentityNotFound        = errors.New("identity not linked")
+	ErrLastLoginMethod         = errors.New("cannot unlink the only login method, set a password first")
+)
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+type OAuthService struct {
+	userRepo     *repositories.UserRepository
+	identityRepo *repositories.IdentityRepository
+	tokenRepo    *repositories.AccessTokenRepository
+	mfaRepo      *repositories.MFARepository
+	auditRepo    *repositories.AuditRepository
+	cache        *cache.CacheManager
+}
+
+func NewOAuthService() *OAuthService {
+	return &OAuthService{
+		userRepo:     repositories.NewUserRepository(),
+		identityRepo: repositories.NewIdentityRepository(),
+		tokenRepo:    repositories.NewAccessTokenRepository(),
+		mfaRepo:      repositories.NewMFARepository(),
+		auditRepo:    repositories.NewAuditRepository(),
+		cache:        cache.NewCacheManager(),
+	}
+}
+
+type OAuthStartResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+type OAuthCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// oauthState 保存在Redis中的授权请求上下文，回调时一次性取出
+type oauthState struct {
+	Provider    string `json:"provider"`
+	Verifier    string `json:"verifier"`
+	RedirectURI string `json:"redirect_uri"`
+	LinkUserID  int64  `json:"link_user_id"` // 大于0时为已登录用户绑定新的身份
+}
+
+// Start 生成授权地址。linkUserID 大于0时，回调后将身份绑定到该用户
+func (s *OAuthService) Start(providerName string, linkUserID int64) (*OAuthStartResponse, error) {
+	provider, err := oauth.GetProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := oauth.GenerateState()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oauth.GenerateVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	redirectURI := config.AppConfig.OAuth.RedirectURL
+	err = s.cache.Set(context.Background(), oauthStateKey(state), oauthState{
+		Provider:    providerName,
+		Verifier:    verifier,
+		RedirectURI: redirectURI,
+		LinkUserID:  linkUserID,
+	}, oauthStateTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuthStartResponse{
+		AuthorizationURL: provider.AuthCodeURL(state, oauth.ChallengeS256(verifier), redirectURI),
+		State:            state,
+	}, nil
+}
+
+// Callback 用授权码换取第三方身份后登录或绑定：
+// 已绑定的身份直接登录；否则按已验证的邮箱关联现有用户；都没有时创建新用户
+func (s *OAuthService) Callback(providerName string, req *OAuthCallbackRequest, ip, userAgent string) (*AuthResponse, error) {
+	provider, err := oauth.GetProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	var state oauthState
+	if err := s.cache.GetAndDelete(ctx, oauthStateKey(req.State), &state); err != nil || state.Provider != providerName {
+		return nil, ErrInvalidOAuthState
+	}
+
+	token, err := provider.Exchange(ctx, req.Code, state.Verifier, state.RedirectURI)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := provider.FetchIdentity(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	linked, err := s.identityRepo.GetByProviderUserID(providerName, identity.ProviderUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *models.User
+	switch {
+	case linked != nil:
+		if state.LinkUserID > 0 && linked.UserID != state.LinkUserID {
+			return nil, ErrIdentityLinkedElsewhere
+		}
+		if user, err = s.userRepo.GetByID(linked.UserID); err != nil {
+			return nil, err
+		}
+	case state.LinkUserID > 0:
+		if user, err = s.userRepo.GetByID(state.LinkUserID); err != nil {
+			return nil, err
+		}
+		if err := s.link(user, identity, ip); err != nil {
+			return nil, err
+		}
+	default:
+		if !identity.EmailVerified || identity.Email == "" {
+			return nil, ErrOAuthEmailRequired
+		}
+		if user, err = s.findOrCreateUser(identity, ip); err != nil {
+			return nil, err
+		}
+		if err := s.link(user, identity, ip); err != nil {
+			return nil, err
+		}
+	}
+
+	return loginResponse(user, ip, userAgent)
+}
+
+// ListIdentities 获取用户绑定的第三方身份
+func (s *OAuthService) ListIdentities(userID int64) ([]models.UserIdentity, error) {
+	return s.identityRepo.GetByUserID(userID)
+}
+
+// Unlink 解绑第三方身份。没有设置密码的用户不能解绑最后一个身份
+func (s *OAuthService) Unlink(userID int64, providerName, ip string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	identities, err := s.identityRepo.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if user.PasswordHash == "" && len(identities) <= 1 {
+		return ErrLastLoginMethod
+	}
+
+	deleted, err := s.identityRepo.Delete(userID, providerName)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrIdentityNotFound
+	}
+
+	recordAudit(s.auditRepo, &userID, AuditOAuthUnlink, ip, providerName)
+	return nil
+}
+
+// findOrCreateUser 按已验证的邮箱查找用户，不存在时创建一个没有密码的新用户
+func (s *OAuthService) findOrCreateUser(identity *oauth.Identity, ip string) (*models.User, error) {
+	user, err := s.userRepo.GetByEmail(identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		// 本地账户未验证邮箱时，无法确认注册者就是邮箱的主人。
+		// 以第三方的验证结果为准，同时清除原密码和注册者留下的全部登录方式，防止他人抢注该邮箱后继续登录；
+		// 真正的主人可以通过重置密码重新设置
+		if user.EmailVerifiedAt == nil {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+			user.PasswordHash = ""
+			if err := s.userRepo.Update(user); err != nil {
+				return nil, err
+			}
+			if err := s.revokeUnverifiedAccess(user.ID, ip); err != nil {
+				return nil, err
+			}
+		}
+		return user, nil
+	}
+
+	username, err := s.availableUsername(identity.Login)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user = &models.User{
+		Username:        username,
+		Email:           identity.Email,
+		AvatarURL:       identity.AvatarURL,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// revokeUnverifiedAccess 撤销未验证邮箱的注册者留下的会话、访问令牌、两步验证和已绑定的第三方身份，
+// 避免其继续访问账户，或用自己的认证器把真正的主人挡在外面
+func (s *OAuthService) revokeUnverifiedAccess(userID int64, ip string) error {
+	if _, err := NewSessionService().RevokeAll(userID, "", ip); err != nil {
+		return err
+	}
+	if err := s.tokenRepo.RevokeAll(userID); err != nil {
+		return err
+	}
+	if err := s.mfaRepo.Delete(userID); err != nil {
+		return err
+	}
+
+	identities, err := s.identityRepo.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, identity := range identities {
+		if _, err := s.identityRepo.Delete(userID, identity.Provider); err != nil {
+			return err
+		}
+	}
+
+	recordAudit(s.auditRepo, &userID, AuditOAuthTakeover, ip, fmt.Sprintf("unverified account claimed, removed %d identities", len(identities)))
+	return nil
+}
+
+// availableUsername 以第三方用户名为基础生成一个未被占用的用户名，其他用户改名前的旧用户名同样不可用
+func (s *OAuthService) availableUsername(login string) (string, error) {
+	base := oauthUsernameBase(login)
+	userService := NewUserService()
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		err := userService.checkUsernameAvailable(candidate, 0)
+		if err == nil {
+			return candidate, nil
+		}
+		if !errors.Is(err, ErrUsernameTaken) {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s-%04d", base, rand.IntN(10000))
+	}
+	return "", errors.New("failed to generate a unique username")
+}
+
+// oauthUsernameBase 去掉第三方用户名中不允许的字符，并补齐或截断到合法长度，留出随机后缀的位置
+func oauthUsernameBase(login string) string {
+	base := usernameInvalidChars.ReplaceAllString(login, "")
+	if len(base) < 3 {
+		base = "dev" + base
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	return base
+}
+
+func (s *OAuthService) link(user *models.User, identity *oauth.Identity, ip string) error {
+	err := s.identityRepo.Create(&models.UserIdentity{
+		UserID:         user.ID,
+		Provider:       identity.Provider,
+		ProviderUserID: identity.ProviderUserID,
+		Login:          identity.Login,
+		Email:          strings.ToLower(identity.Email),
+	})
+	if err != nil {
+		return err
+	}
+
+	recordAudit(s.auditRepo, &user.ID, AuditOAuthLink, ip, identity.Provider+":"+identity.Login)
+	return nil
+}
+
+func oauthStateKey(state string) string {
+	return fmt.Sprintf("oauth_state:%s", state)
+}
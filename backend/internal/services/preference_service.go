@@ -0,0 +1,457 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/internal/utils"
+	"devswipe-backend/pkg/cache"
+	"devswipe-backend/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 标签亲和度权重参数
+const (
+	tagWeightLike          = 1.0
+	tagWeightSuperLike     = 2.0
+	tagWeightNotInterested = -1.0
+
+	// 权重半衰期（天）
+	tagWeightHalfLifeDays = 30.0
+	// 绝对值低于该阈值的权重会被清理，避免画像无限膨胀
+	tagWeightPruneThreshold = 0.01
+)
+
+// 冷启动种子权重
+const (
+	tagSeedFollowed  = 1.0
+	tagSeedTechStack = 0.5
+)
+
+type PreferenceService struct {
+	userRepo   *repositories.UserRepository
+	tagService *TagService
+	cache      *cache.CacheManager
+}
+
+func NewPreferenceService() *PreferenceService {
+	return &PreferenceService{
+		userRepo:   repositories.NewUserRepository(),
+		tagService: NewTagService(),
+		cache:      cache.NewCacheManager(),
+	}
+}
+
+type UpdatePreferencesRequest struct {
+	FollowedTags      []string `json:"followed_tags" binding:"omitempty,max=50,dive,min=1,max=50,excludes=,"`
+	MutedTags         []string `json:"muted_tags" binding:"omitempty,max=50,dive,min=1,max=50,excludes=,"`
+	PreferredStatuses []string `json:"preferred_statuses" binding:"omitempty,dive,oneof=concept demo mvp launched"`
+}
+
+type PreferencesResponse struct {
+	FollowedTags      []string           `json:"followed_tags"`
+	MutedTags         []string           `json:"muted_tags"`
+	PreferredStatuses []string           `json:"preferred_statuses"`
+	TagAffinity       map[string]float64 `json:"tag_affinity"`
+}
+
+// GetPreferences 获取用户的显式偏好和学习到的标签画像
+func (s *PreferenceService) GetPreferences(userID int64) (*PreferencesResponse, error) {
+	preferences, err := s.userRepo.GetUserPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, err := s.tagService.CanonicalKeys(profileTagKeys(preferences))
+	if err != nil {
+		return nil, err
+	}
+
+	return &PreferencesResponse{
+		FollowedTags:      splitList(preferences.FollowedTags),
+		MutedTags:         splitList(preferences.MutedTags),
+		PreferredStatuses: splitList(preferences.PreferredStatuses),
+		TagAffinity:       tagAffinity(preferences, canonical),
+	}, nil
+}
+
+// UpdatePreferences 更新用户的显式偏好，未提供的字段保持不变
+func (s *PreferenceService) UpdatePreferences(userID int64, req *UpdatePreferencesRequest) (*PreferencesResponse, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var preferences models.UserPreferences
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			First(&preferences).Error
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			preferences = models.UserPreferences{UserID: userID, PreferredTags: "{}"}
+		}
+
+		if req.FollowedTags != nil {
+			preferences.FollowedTags = joinTagList(req.FollowedTags)
+		}
+		if req.MutedTags != nil {
+			preferences.MutedTags = joinTagList(req.MutedTags)
+		}
+		if req.PreferredStatuses != nil {
+			preferences.PreferredStatuses = strings.Join(req.PreferredStatuses, ",")
+		}
+
+		return tx.Save(&preferences).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 偏好变化后清理推荐相关缓存
+	ctx := context.Background()
+	s.cache.DeleteByPattern(ctx, fmt.Sprintf("user_recommendations:%d:*", userID))
+	s.cache.DeleteByPattern(ctx, fmt.Sprintf("user_feed:%d:*", userID))
+
+	return s.GetPreferences(userID)
+}
+
+// GetTagPreferences 合并学习到的画像与冷启动种子（技术栈、关注标签），屏蔽标签单独返回。
+// 所有标签都归并为规范标签的键
+func (s *PreferenceService) GetTagPreferences(userID int64) (map[string]float64, map[string]bool, error) {
+	preferences, err := s.userRepo.GetUserPreferences(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var techStack []string
+	if user, err := s.userRepo.GetByID(userID); err == nil {
+		techStack = splitList(user.TechStack)
+	}
+	followed := splitList(preferences.FollowedTags)
+
+	// 画像、种子和屏蔽标签一次批量归并，避免每个标签单独查询
+	names := append(profileTagKeys(preferences), techStack...)
+	names = append(names, followed...)
+	names = append(names, splitList(preferences.MutedTags)...)
+	canonical, err := s.tagService.CanonicalKeys(names)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	weights := MergeTagSeeds(tagAffinity(preferences, canonical),
+		canonicalTagKeys(techStack, canonical), canonicalTagKeys(followed, canonical))
+	muted := mutedTags(preferences, canonical)
+	for key := range muted {
+		delete(weights, key)
+	}
+
+	return weights, muted, nil
+}
+
+// MergeTagSeeds 将技术栈和关注标签作为冷启动种子合并进标签权重：
+// 技术栈只补充画像中没有的标签，关注标签至少取最高权重。种子标签应已归并为规范标签的键
+func MergeTagSeeds(weights map[string]float64, techStack, followedTags []string) map[string]float64 {
+	for _, tag := range techStack {
+		key := tagKey(tag)
+		if _, exists := weights[key]; !exists && key != "" {
+			weights[key] = tagSeedTechStack
+		}
+	}
+
+	for _, tag := range followedTags {
+		key := tagKey(tag)
+		if key != "" {
+			weights[key] = math.Max(weights[key], tagSeedFollowed)
+		}
+	}
+
+	return weights
+}
+
+// GetPreferredStatuses 获取用户偏好的项目阶段
+func (s *PreferenceService) GetPreferredStatuses(userID int64) (map[string]bool, error) {
+	preferences, err := s.userRepo.GetUserPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[string]bool)
+	for _, status := range splitList(preferences.PreferredStatuses) {
+		statuses[status] = true
+	}
+	return statuses, nil
+}
+
+// GetMutedTags 获取用户屏蔽的标签，键为规范标签的键
+func (s *PreferenceService) GetMutedTags(userID int64) (map[string]bool, error) {
+	preferences, err := s.userRepo.GetUserPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	canonical, err := s.tagService.CanonicalKeys(splitList(preferences.MutedTags))
+	if err != nil {
+		return nil, err
+	}
+	return mutedTags(preferences, canonical), nil
+}
+
+// mutedTags 将偏好记录中的屏蔽标签归并为规范标签的键，屏蔽同义词或改名前的写法同样生效。
+// canonical 为 TagService.CanonicalKeys 的结果
+func mutedTags(preferences *models.UserPreferences, canonical map[string]string) map[string]bool {
+	keys := canonicalTagKeys(splitList(preferences.MutedTags), canonical)
+	muted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		muted[key] = true
+	}
+	return muted
+}
+
+// canonicalTagKeys 按 canonical 将标签列表归并为规范标签的键，忽略空项，没有对应规范键的标签保留查找键
+func canonicalTagKeys(names []string, canonical map[string]string) []string {
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		key := tagKey(name)
+		if key == "" {
+			continue
+		}
+		if c := canonical[key]; c != "" {
+			key = c
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// FilterMutedProjects 移除带有屏蔽标签的项目
+func FilterMutedProjects(projects []models.Project, muted map[string]bool) []models.Project {
+	if len(muted) == 0 {
+		return projects
+	}
+
+	filtered := make([]models.Project, 0, len(projects))
+	for _, project := range projects {
+		if !hasMutedTag(project.Tags, muted) {
+			filtered = append(filtered, project)
+		}
+	}
+	return filtered
+}
+
+func hasMutedTag(tags []models.ProjectTag, muted map[string]bool) bool {
+	for _, tag := range tags {
+		if muted[tagKey(tag.TagName)] {
+			return true
+		}
+	}
+	return false
+}
+
+// interactionTagWeight 返回一次交互对项目标签施加的权重，0表示不影响画像
+func interactionTagWeight(interactionType, structuredFeedback string) float64 {
+	switch interactionType {
+	case "like":
+		return tagWeightLike
+	case "super_like":
+		return tagWeightSuperLike
+	case "dislike":
+		if structuredFeedback == "not_interested" {
+			return tagWeightNotInterested
+		}
+	}
+	return 0
+}
+
+// TagProfile 标签亲和度画像：原始（未归一化）权重及其最近一次衰减的时间
+type TagProfile struct {
+	Weights   map[string]float64
+	DecayedAt *time.Time
+}
+
+// Record 在at时刻先对已有权重做衰减，再叠加一次交互的权重；交互不影响画像时返回false
+func (p *TagProfile) Record(tags []models.ProjectTag, interactionType, structuredFeedback string, at time.Time) bool {
+	weight := interactionTagWeight(interactionType, structuredFeedback)
+	if weight == 0 || len(tags) == 0 {
+		return false
+	}
+
+	if p.Weights == nil {
+		p.Weights = make(map[string]float64)
+	}
+	p.Weights = decayTagWeights(p.Weights, p.DecayedAt, at)
+	for _, tag := range tags {
+		p.Weights[tagKey(tag.TagName)] += weight
+	}
+	pruneTagWeights(p.Weights)
+	p.DecayedAt = &at
+	return true
+}
+
+// Affinity 返回now时刻衰减后的权重，归一化到[-1, 1]
+func (p TagProfile) Affinity(now time.Time) map[string]float64 {
+	weights := make(map[string]float64, len(p.Weights))
+	for tag, w := range p.Weights {
+		weights[tag] = w
+	}
+	weights = decayTagWeights(weights, p.DecayedAt, now)
+
+	maxAbs := 0.0
+	for _, w := range weights {
+		maxAbs = math.Max(maxAbs, math.Abs(w))
+	}
+	if maxAbs > 0 {
+		for tag, w := range weights {
+			weights[tag] = w / maxAbs
+		}
+	}
+
+	return weights
+}
+
+// RecordInteraction 根据一次交互增量更新用户的标签亲和度画像
+func (s *PreferenceService) RecordInteraction(userID int64, tags []models.ProjectTag, interactionType, structuredFeedback string) error {
+	if interactionTagWeight(interactionType, structuredFeedback) == 0 || len(tags) == 0 {
+		return nil
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var preferences models.UserPreferences
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			First(&preferences).Error
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			preferences = models.UserPreferences{UserID: userID}
+		}
+
+		profile := TagProfile{
+			Weights:   parseTagWeights(preferences.PreferredTags),
+			DecayedAt: preferences.DecayedAt,
+		}
+		profile.Record(tags, interactionType, structuredFeedback, time.Now())
+
+		preferences.PreferredTags = string(utils.MustMarshalJSON(profile.Weights))
+		preferences.DecayedAt = profile.DecayedAt
+		return tx.Save(&preferences).Error
+	})
+}
+
+// GetTagAffinity 获取用户当前（已衰减）的标签亲和度，权重归一化到[-1, 1]
+func (s *PreferenceService) GetTagAffinity(userID int64) (map[string]float64, error) {
+	preferences, err := s.userRepo.GetUserPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, err := s.tagService.CanonicalKeys(profileTagKeys(preferences))
+	if err != nil {
+		return nil, err
+	}
+	return tagAffinity(preferences, canonical), nil
+}
+
+// tagAffinity 计算偏好记录中衰减并归一化后的标签权重。画像中的键按 canonical 归并到规范标签，
+// 标签改名或合并之前记录的权重累加到合并后的标签上
+func tagAffinity(preferences *models.UserPreferences, canonical map[string]string) map[string]float64 {
+	profile := TagProfile{
+		Weights:   canonicalizeTagWeights(parseTagWeights(preferences.PreferredTags), canonical),
+		DecayedAt: preferences.DecayedAt,
+	}
+	return profile.Affinity(time.Now())
+}
+
+// profileTagKeys 画像中记录过权重的标签键
+func profileTagKeys(preferences *models.UserPreferences) []string {
+	weights := parseTagWeights(preferences.PreferredTags)
+	keys := make([]string, 0, len(weights))
+	for key := range weights {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// canonicalizeTagWeights 按 canonical 给出的规范键重新汇总权重，没有对应规范键的标签保持不变
+func canonicalizeTagWeights(weights map[string]float64, canonical map[string]string) map[string]float64 {
+	merged := make(map[string]float64, len(weights))
+	for key, w := range weights {
+		if c := canonical[key]; c != "" {
+			key = c
+		}
+		merged[key] += w
+	}
+	return merged
+}
+
+// splitList 解析逗号分隔的列表，忽略空项
+func splitList(raw string) []string {
+	items := []string{}
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// joinTagList 去重（忽略大小写）后拼接标签列表
+func joinTagList(tags []string) string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := tagKey(tag)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, tag)
+	}
+	return strings.Join(result, ",")
+}
+
+// parseTagWeights 解析画像JSON，旧数据中的数组或非法内容视为空画像
+func parseTagWeights(raw string) map[string]float64 {
+	weights := make(map[string]float64)
+	if raw == "" {
+		return weights
+	}
+	if err := json.Unmarshal([]byte(raw), &weights); err != nil {
+		return make(map[string]float64)
+	}
+	return weights
+}
+
+// decayTagWeights 按半衰期对权重做指数衰减
+func decayTagWeights(weights map[string]float64, decayedAt *time.Time, now time.Time) map[string]float64 {
+	if decayedAt == nil || len(weights) == 0 {
+		return weights
+	}
+
+	days := now.Sub(*decayedAt).Hours() / 24
+	if days <= 0 {
+		return weights
+	}
+
+	factor := math.Pow(0.5, days/tagWeightHalfLifeDays)
+	for tag, w := range weights {
+		weights[tag] = w * factor
+	}
+	pruneTagWeights(weights)
+	return weights
+}
+
+func pruneTagWeights(weights map[string]float64) {
+	for tag, w := range weights {
+		if math.Abs(w) < tagWeightPruneThreshold {
+			delete(weights, tag)
+		}
+	}
+}
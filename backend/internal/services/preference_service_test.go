@@ -0,0 +1,89 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"devswipe-backend/internal/models"
+)
+
+func TestCanonicalizeTagWeights(t *testing.T) {
+	weights := map[string]float64{
+		"reactjs":    1,
+		"react":      2,
+		"ml":         -0.5,
+		"kubernetes": 1.5,
+	}
+	canonical := map[string]string{
+		"reactjs": "react",
+		"react":   "react",
+		"ml":      "machine learning",
+	}
+
+	got := canonicalizeTagWeights(weights, canonical)
+	want := map[string]float64{
+		"react":            3,
+		"machine learning": -0.5,
+		"kubernetes":       1.5,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("canonicalizeTagWeights() = %v, want %v", got, want)
+	}
+}
+
+func TestCanonicalTagKeys(t *testing.T) {
+	canonical := map[string]string{
+		"reactjs":          "react",
+		"machine learning": "ml",
+	}
+
+	got := canonicalTagKeys([]string{"ReactJS", " ", "Machine  Learning", "Rust"}, canonical)
+	want := []string{"react", "ml", "rust"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("canonicalTagKeys() = %v, want %v", got, want)
+	}
+}
+
+func TestMergeTagSeeds(t *testing.T) {
+	weights := map[string]float64{"go": 0.2, "react": 1.5}
+
+	got := MergeTagSeeds(weights, []string{"Go", "Rust"}, []string{"react", "Machine  Learning"})
+	want := map[string]float64{
+		"go":               0.2, // 画像中已有的标签不被技术栈覆盖
+		"rust":             tagSeedTechStack,
+		"react":            1.5, // 关注标签至少取种子权重
+		"machine learning": tagSeedFollowed,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("MergeTagSeeds() = %v, want %v", got, want)
+	}
+}
+
+func TestFilterMutedProjects(t *testing.T) {
+	projects := []models.Project{
+		{ID: 1, Tags: []models.ProjectTag{{TagName: "React"}}},
+		{ID: 2, Tags: []models.ProjectTag{{TagName: "Go"}, {TagName: "Machine Learning"}}},
+		{ID: 3},
+	}
+
+	got := authorProjectIDs(FilterMutedProjects(projects, map[string]bool{"machine learning": true}))
+	if want := []int64{1, 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("FilterMutedProjects() = %v, want %v", got, want)
+	}
+}
+
+func TestTagProfileRecordUsesTagKey(t *testing.T) {
+	now := time.Now()
+	var profile TagProfile
+	profile.Record([]models.ProjectTag{{TagName: "Machine Learning"}}, "like", "", now)
+	profile.Record([]models.ProjectTag{{TagName: "machine  learning"}}, "super_like", "", now)
+
+	if got := profile.Weights["machine learning"]; math.Abs(got-(tagWeightLike+tagWeightSuperLike)) > 1e-9 {
+		t.Fatalf("weight = %v, want %v", got, tagWeightLike+tagWeightSuperLike)
+	}
+	if len(profile.Weights) != 1 {
+		t.Fatalf("weights = %v, want a single key", profile.Weights)
+	}
+}
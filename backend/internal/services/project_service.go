@@ -8,18 +8,23 @@ import (
 	"devswipe-backend/pkg/database"
 	"errors"
 	"fmt"
+	"log"
 	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type ProjectService struct {
 	projectRepo *repositories.ProjectRepository
+	tagService  *TagService
 	cache       *cache.CacheManager
 }
 
 func NewProjectService() *ProjectService {
 	return &ProjectService{
 		projectRepo: repositories.NewProjectRepository(),
+		tagService:  NewTagService(),
 		cache:       cache.NewCacheManager(),
 	}
 }
@@ -46,13 +51,18 @@ type UpdateProjectRequest struct {
 }
 
 type FeedParams struct {
-	Page      int      `json:"page"`
-	Limit     int      `json:"limit"`
-	Tags      []string `json:"tags"`
-	SessionID string   `json:"session_id"`
+	Page       int                   `json:"page"`
+	Limit      int                   `json:"limit"`
+	Tags       []string              `json:"tags"`
+	SessionID  string                `json:"session_id"`
+	Assignment *ExperimentAssignment `json:"-"` // A/B实验分组，为空时使用默认策略
 }
 
 func (s *ProjectService) CreateProject(userID int64, req *CreateProjectRequest) (*models.Project, error) {
+	if err := NewAccountService().CanPublish(userID); err != nil {
+		return nil, err
+	}
+
 	project := &models.Project{
 		UserID:      userID,
 		Title:       req.Title,
@@ -68,37 +78,54 @@ func (s *ProjectService) CreateProject(userID int64, req *CreateProjectRequest)
 		project.Status = "demo"
 	}
 
-	// 使用事务创建项目和标签
-	err := s.projectRepo.Create(project)
+	// 使用事务创建项目和标签，标签统一归并到规范标签，非法标签直接拒绝，失败时不会留下新建的标签
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		tags, err := s.tagService.Resolve(tx, req.Tags)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+		return createProjectTags(tx, project.ID, tags)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// 创建标签
-	if len(req.Tags) > 0 {
-		for _, tagName := range req.Tags {
-			tag := &models.ProjectTag{
-				ProjectID: project.ID,
-				TagName:   tagName,
-				TagType:   "tech", // 默认技术标签
-			}
-			// 这里简化处理，实际项目中可能需要更复杂的标签类型判断
-			if err := database.DB.Create(tag).Error; err != nil {
-				// 记录错误但不影响项目创建
-				fmt.Printf("Failed to create tag %s: %v\n", tagName, err)
-			}
-		}
+	// 发布第一个项目后升级为创作者，新角色在下次获取令牌时生效
+	if err := repositories.NewUserRepository().PromoteToCreator(userID); err != nil {
+		log.Printf("Failed to promote user %d to creator: %v", userID, err)
+	}
+
+	// 清除关注者的动态流缓存
+	if err := NewFollowingFeedService().InvalidateFollowers(userID); err != nil {
+		log.Printf("Failed to invalidate following feeds: %v", err)
 	}
 
 	return project, nil
 }
 
+// createProjectTags 在事务中为项目写入指向规范标签的项目标签
+func createProjectTags(tx *gorm.DB, projectID int64, tags []models.Tag) error {
+	for _, tag := range newProjectTags(projectID, tags) {
+		if err := tx.Create(&tag).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *ProjectService) GetProjectByID(id int64) (*models.Project, error) {
 	return s.projectRepo.GetByID(id)
 }
 
-func (s *ProjectService) GetUserProjects(userID int64, limit, offset int) ([]models.Project, error) {
-	return s.projectRepo.GetByUserID(userID, limit, offset)
+// GetUserProjects 获取用户发布的项目，查看者需要能看到该用户的完整主页，只有本人能看到已隐藏的项目
+func (s *ProjectService) GetUserProjects(viewerID, userID int64, limit, offset int) ([]models.Project, error) {
+	if err := NewUserService().CheckProfileAccess(viewerID, userID); err != nil {
+		return nil, err
+	}
+	return s.projectRepo.GetByUserID(userID, viewerID == userID, limit, offset)
 }
 
 func (s *ProjectService) UpdateProject(userID, projectID int64, req *UpdateProjectRequest) (*models.Project, error) {
@@ -112,51 +139,73 @@ func (s *ProjectService) UpdateProject(userID, projectID int64, req *UpdateProje
 		return nil, errors.New("unauthorized to update this project")
 	}
 
-	// 更新字段
-	if req.Title != nil {
+	// 更新字段，同时记录发生变化的字段用于关注动态流
+	var changed []string
+	if req.Title != nil && *req.Title != project.Title {
 		project.Title = *req.Title
+		changed = append(changed, "title")
 	}
-	if req.Description != nil {
+	if req.Description != nil && *req.Description != project.Description {
 		project.Description = *req.Description
+		changed = append(changed, "description")
 	}
-	if req.CoverImage != nil {
+	if req.CoverImage != nil && *req.CoverImage != project.CoverImage {
 		project.CoverImage = *req.CoverImage
+		changed = append(changed, "cover_image")
 	}
-	if req.ImageURLs != nil {
+	if req.ImageURLs != nil && strings.Join(req.ImageURLs, ",") != project.ImageURLs {
 		project.ImageURLs = strings.Join(req.ImageURLs, ",")
+		changed = append(changed, "image_urls")
 	}
-	if req.ProjectURL != nil {
+	if req.ProjectURL != nil && *req.ProjectURL != project.ProjectURL {
 		project.ProjectURL = *req.ProjectURL
+		changed = append(changed, "project_url")
 	}
-	if req.Status != nil {
+	if req.Status != nil && *req.Status != project.Status {
 		project.Status = *req.Status
+		changed = append(changed, "status")
 	}
 	if req.IsPublic != nil {
 		project.IsPublic = *req.IsPublic
 	}
 
-	err = s.projectRepo.Update(project)
+	// 项目和标签在同一个事务中更新，标签非法时整体回滚
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(project).Error; err != nil {
+			return err
+		}
+		if req.Tags == nil {
+			return nil
+		}
+
+		tags, err := s.tagService.Resolve(tx, req.Tags)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", projectID).Delete(&models.ProjectTag{}).Error; err != nil {
+			return err
+		}
+		return createProjectTags(tx, project.ID, tags)
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	// 更新标签
 	if req.Tags != nil {
-		// 删除旧标签
-		if err := database.DB.Where("project_id = ?", projectID).Delete(&models.ProjectTag{}).Error; err != nil {
+		changed = append(changed, "tags")
+	}
+
+	// 公开项目的更新推送到关注者的动态流
+	if project.IsPublic && len(changed) > 0 {
+		update := &models.ProjectUpdate{
+			ProjectID: project.ID,
+			UserID:    userID,
+			Summary:   strings.Join(changed, ","),
+		}
+		if err := s.projectRepo.CreateUpdate(update); err != nil {
 			return nil, err
 		}
-
-		// 创建新标签
-		for _, tagName := range req.Tags {
-			tag := &models.ProjectTag{
-				ProjectID: project.ID,
-				TagName:   tagName,
-				TagType:   "tech",
-			}
-			if err := database.DB.Create(tag).Error; err != nil {
-				return nil, err
-			}
+		if err := NewFollowingFeedService().InvalidateFollowers(userID); err != nil {
+			log.Printf("Failed to invalidate following feeds: %v", err)
 		}
 	}
 
@@ -179,11 +228,16 @@ func (s *ProjectService) DeleteProject(userID, projectID int64) error {
 
 func (s *ProjectService) GetUserFeed(userID int64, params FeedParams) ([]models.Project, error) {
 	// 尝试从缓存获取
-	cacheKey := fmt.Sprintf("user_feed:%d:%d", userID, params.Page)
+	rankingParams := params.Assignment.RankingParams()
+	cacheKey := fmt.Sprintf("user_feed:%d:%d:%s", userID, params.Page, rankingParams.Variant)
+	if len(params.Tags) > 0 {
+		cacheKey += ":tags:" + strings.ToLower(strings.Join(params.Tags, ","))
+	}
 	var cachedProjects []models.Project
 	ctx := context.Background()
 
 	if err := s.cache.Get(ctx, cacheKey, &cachedProjects); err == nil {
+		s.recordImpressions(cachedProjects)
 		return cachedProjects, nil
 	}
 
@@ -192,38 +246,75 @@ func (s *ProjectService) GetUserFeed(userID int64, params FeedParams) ([]models.
 	var err error
 
 	if len(params.Tags) > 0 {
-		// 按标签过滤
-		projects, err = s.projectRepo.GetProjectsByTags(params.Tags, params.Limit, (params.Page-1)*params.Limit)
-	} else if userID > 0 {
+		// 按标签过滤，标签名按同义词归并到规范标签，全部不存在时返回空列表
+		var tagIDs []int64
+		projects = []models.Project{}
+		if tagIDs, err = s.tagService.LookupIDs(params.Tags); err == nil && len(tagIDs) > 0 {
+			projects, err = s.projectRepo.GetProjectsByTags(tagIDs, params.Limit, (params.Page-1)*params.Limit)
+		}
+	} else if userID > 0 && (params.Assignment == nil || params.Assignment.Strategy != "latest") {
 		// 使用推荐算法
 		recommendationService := NewRecommendationService()
-		recommendedIDs, recErr := recommendationService.GetUserRecommendations(userID, params.Limit)
+		recommendedIDs, recErr := recommendationService.GetUserRecommendationsWithParams(userID, params.Limit, rankingParams)
 		if recErr == nil && len(recommendedIDs) > 0 {
-			// 根据推荐ID获取项目详情
-			err = database.DB.Preload("User").Preload("Tags").
-				Where("id IN ?", recommendedIDs).
-				Find(&projects).Error
+			// 根据推荐ID获取项目详情，保持推荐顺序
+			projects, err = s.projectRepo.GetByIDs(recommendedIDs)
 		} else {
 			// 回退到基础推荐
 			projects, err = s.projectRepo.GetRecommendedProjects(userID, params.Limit)
 		}
+	} else if userID == 0 {
+		// 未登录用户展示本周热榜，热榜为空时回退到最新项目
+		projects, _, err = NewTrendingService().GetTrending(userID, "week", "", params.Limit, (params.Page-1)*params.Limit)
+		if err != nil || len(projects) == 0 {
+			projects, err = s.projectRepo.GetRecommendedProjects(userID, params.Limit)
+		}
 	} else {
-		// 未登录用户，获取最新项目
-		projects, err = s.projectRepo.GetRecommendedProjects(0, params.Limit)
+		// 实验策略为latest，获取最新项目
+		projects, err = s.projectRepo.GetRecommendedProjects(userID, params.Limit)
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	// 移除带有用户屏蔽标签的项目
+	if userID > 0 {
+		mutedTags, err := NewPreferenceService().GetMutedTags(userID)
+		if err != nil {
+			return nil, err
+		}
+		projects = FilterMutedProjects(projects, mutedTags)
+
+		// 移除拉黑、静音的作者以及拉黑了该用户的作者发布的项目
+		hidden, err := NewBlockService().HiddenUsers(userID)
+		if err != nil {
+			return nil, err
+		}
+		projects = FilterHiddenAuthors(projects, hidden)
+	}
+
 	// 缓存结果
 	s.cache.Set(ctx, cacheKey, projects, 10*time.Minute)
 
+	s.recordImpressions(projects)
 	return projects, nil
 }
 
-func (s *ProjectService) SearchProjects(keyword string, limit, offset int) ([]models.Project, error) {
-	return s.projectRepo.SearchProjects(keyword, limit, offset)
+// recordImpressions 记录浏览流的曝光，供探索层判断项目是否曝光不足。每次返回浏览流都要记录，包括命中缓存的请求
+func (s *ProjectService) recordImpressions(projects []models.Project) {
+	if err := NewExplorationService().RecordImpressions(projects); err != nil {
+		log.Printf("Failed to record impressions: %v", err)
+	}
+}
+
+// SearchProjects 搜索公开项目，viewerID 拉黑、静音的作者和拉黑了 viewerID 的作者的项目不返回
+func (s *ProjectService) SearchProjects(viewerID int64, keyword string, limit, offset int) ([]models.Project, error) {
+	hiddenIDs, err := NewBlockService().HiddenUserIDs(viewerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.projectRepo.SearchProjects(keyword, hiddenIDs, limit, offset)
 }
 
 func (s *ProjectService) IncrementViewCount(projectID int64) error {
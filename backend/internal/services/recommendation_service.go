@@ -7,6 +7,7 @@ import (
 	"sort"
 	"time"
 
+	"devswipe-backend/internal/config"
 	"devswipe-backend/internal/models"
 	"devswipe-backend/internal/repositories"
 	"devswipe-backend/pkg/cache"
@@ -14,33 +15,109 @@ import (
 )
 
 type RecommendationService struct {
-	userRepo        *repositories.UserRepository
-	projectRepo     *repositories.ProjectRepository
-	interactionRepo *repositories.InteractionRepository
-	cache           *cache.CacheManager
+	userRepo           *repositories.UserRepository
+	projectRepo        *repositories.ProjectRepository
+	interactionRepo    *repositories.InteractionRepository
+	preferenceService  *PreferenceService
+	explorationService *ExplorationService
+	cache              *cache.CacheManager
 }
 
 func NewRecommendationService() *RecommendationService {
 	return &RecommendationService{
-		userRepo:        repositories.NewUserRepository(),
-		projectRepo:     repositories.NewProjectRepository(),
-		interactionRepo: repositories.NewInteractionRepository(),
-		cache:           cache.NewCacheManager(),
+		userRepo:           repositories.NewUserRepository(),
+		projectRepo:        repositories.NewProjectRepository(),
+		interactionRepo:    repositories.NewInteractionRepository(),
+		preferenceService:  NewPreferenceService(),
+		explorationService: NewExplorationService(),
+		cache:              cache.NewCacheManager(),
 	}
 }
 
 type RecommendationScore struct {
-	ProjectID int64   `json:"project_id"`
-	Score     float64 `json:"score"`
-	Reason    string  `json:"reason"`
+	ProjectID int64    `json:"project_id"`
+	Score     float64  `json:"score"`
+	Reason    string   `json:"reason"`
+	CreatorID int64    `json:"-"` // 多样性重排使用
+	Tags      []string `json:"-"`
+}
+
+// RankingParams 推荐排序参数，A/B实验的变体可以覆盖其中的值
+type RankingParams struct {
+	Variant                   string // 缓存区分用，默认为 default
+	TagWeight                 float64
+	PopularityWeight          float64
+	FreshnessWeight           float64
+	SimilarityWeight          float64
+	StatusBonus               float64
+	DiversityStrength         float64
+	DiversityWindow           int
+	ExplorationRate           float64
+	ExplorationMaxImpressions int
+}
+
+// DefaultRankingParams 返回基于配置的默认排序参数
+func DefaultRankingParams() RankingParams {
+	cfg := config.AppConfig.Recommendation
+	params := BaseRankingParams()
+	params.DiversityStrength = cfg.DiversityStrength
+	params.DiversityWindow = cfg.DiversityWindow
+	params.ExplorationRate = cfg.ExplorationRate
+	params.ExplorationMaxImpressions = cfg.ExplorationMaxImpressions
+	return params
+}
+
+// BaseRankingParams 返回默认的排序权重，不读取配置，多样性重排和探索层均关闭
+func BaseRankingParams() RankingParams {
+	return RankingParams{
+		Variant:          "default",
+		TagWeight:        0.4,
+		PopularityWeight: 0.3,
+		FreshnessWeight:  0.2,
+		SimilarityWeight: 0.1,
+		StatusBonus:      0.1,
+	}
+}
+
+// WithOverrides 应用参数覆盖，未知的键会被忽略
+func (p RankingParams) WithOverrides(variant string, overrides map[string]float64) RankingParams {
+	p.Variant = variant
+	for key, value := range overrides {
+		switch key {
+		case "tag_weight":
+			p.TagWeight = value
+		case "popularity_weight":
+			p.PopularityWeight = value
+		case "freshness_weight":
+			p.FreshnessWeight = value
+		case "similarity_weight":
+			p.SimilarityWeight = value
+		case "status_bonus":
+			p.StatusBonus = value
+		case "diversity_strength":
+			p.DiversityStrength = value
+		case "diversity_window":
+			p.DiversityWindow = int(value)
+		case "exploration_rate":
+			p.ExplorationRate = value
+		case "exploration_max_impressions":
+			p.ExplorationMaxImpressions = int(value)
+		}
+	}
+	return p
 }
 
 // GetUserRecommendations 获取用户推荐项目
 func (s *RecommendationService) GetUserRecommendations(userID int64, limit int) ([]int64, error) {
+	return s.GetUserRecommendationsWithParams(userID, limit, DefaultRankingParams())
+}
+
+// GetUserRecommendationsWithParams 使用指定排序参数获取用户推荐项目
+func (s *RecommendationService) GetUserRecommendationsWithParams(userID int64, limit int, params RankingParams) ([]int64, error) {
 	ctx := context.Background()
 
 	// 尝试从缓存获取
-	cacheKey := fmt.Sprintf("user_recommendations:%d", userID)
+	cacheKey := fmt.Sprintf("user_recommendations:%d:%s", userID, params.Variant)
 	var cachedRecommendations []int64
 	if err := s.cache.Get(ctx, cacheKey, &cachedRecommendations); err == nil {
 		if len(cachedRecommendations) >= limit {
@@ -48,8 +125,13 @@ func (s *RecommendationService) GetUserRecommendations(userID int64, limit int)
 		}
 	}
 
-	// 获取用户偏好
-	userPreferences, err := s.getUserPreferences(userID)
+	// 获取用户偏好（学习画像 + 冷启动种子）及屏蔽标签
+	userPreferences, mutedTags, err := s.getUserPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferredStatuses, err := s.preferenceService.GetPreferredStatuses(userID)
 	if err != nil {
 		return nil, err
 	}
@@ -61,24 +143,30 @@ func (s *RecommendationService) GetUserRecommendations(userID int64, limit int)
 	}
 
 	// 获取候选项目
-	candidateProjects, err := s.getCandidateProjects(userID, 200)
+	candidateProjects, err := s.getCandidateProjects(userID, mutedTags, 200)
 	if err != nil {
 		return nil, err
 	}
 
 	// 计算推荐分数
-	recommendations := s.calculateRecommendationScores(userID, candidateProjects, userPreferences, userInteractions)
+	recommendations := s.calculateRecommendationScores(userID, candidateProjects, userPreferences, preferredStatuses, userInteractions, params)
 
-	// 排序并返回前N个
+	// 排序
 	sort.Slice(recommendations, func(i, j int) bool {
 		return recommendations[i].Score > recommendations[j].Score
 	})
 
+	// 多样性重排，避免同一作者或同一标签的项目扎堆
+	recommendations = DiversifyScores(recommendations, params.DiversityStrength, params.DiversityWindow)
+
+	// 为曝光不足的项目预留探索位，并截取前N个
+	selected, err := s.explorationService.ApplyExploration(recommendations, limit, params.ExplorationRate, params.ExplorationMaxImpressions)
+	if err != nil {
+		return nil, err
+	}
+
 	var result []int64
-	for i, rec := range recommendations {
-		if i >= limit {
-			break
-		}
+	for _, rec := range selected {
 		result = append(result, rec.ProjectID)
 	}
 
@@ -88,46 +176,13 @@ func (s *RecommendationService) GetUserRecommendations(userID int64, limit int)
 	return result, nil
 }
 
-// getUserPreferences 获取用户偏好
-func (s *RecommendationService) getUserPreferences(userID int64) (map[string]float64, error) {
-	preferences := make(map[string]float64)
-
-	// 获取用户交互历史
-	interactions, err := s.interactionRepo.GetByUser(userID, 1000, 0)
-	if err != nil {
-		return preferences, err
-	}
-
-	// 统计用户喜欢的项目标签
-	tagScores := make(map[string]int)
-	totalLikes := 0
-
-	for _, interaction := range interactions {
-		if interaction.InteractionType == "like" || interaction.InteractionType == "super_like" {
-			totalLikes++
-
-			// 获取项目标签
-			var project models.Project
-			if err := database.DB.Preload("Tags").First(&project, interaction.ProjectID).Error; err == nil {
-				for _, tag := range project.Tags {
-					tagScores[tag.TagName]++
-				}
-			}
-		}
-	}
-
-	// 计算标签偏好分数
-	if totalLikes > 0 {
-		for tag, count := range tagScores {
-			preferences[tag] = float64(count) / float64(totalLikes)
-		}
-	}
-
-	return preferences, nil
+// getUserPreferences 获取用户偏好，读取预计算的标签亲和度画像并合并冷启动种子
+func (s *RecommendationService) getUserPreferences(userID int64) (map[string]float64, map[string]bool, error) {
+	return s.preferenceService.GetTagPreferences(userID)
 }
 
-// getCandidateProjects 获取候选项目
-func (s *RecommendationService) getCandidateProjects(userID int64, limit int) ([]models.Project, error) {
+// getCandidateProjects 获取候选项目，排除已交互和带屏蔽标签的项目
+func (s *RecommendationService) getCandidateProjects(userID int64, mutedTags map[string]bool, limit int) ([]models.Project, error) {
 	var projects []models.Project
 
 	// 获取用户已交互的项目ID
@@ -158,6 +213,14 @@ func (s *RecommendationService) getCandidateProjects(userID int64, limit int) ([
 		query = query.Where("id NOT IN ?", interactedProjectIDs)
 	}
 
+	if len(mutedTags) > 0 {
+		muted := make([]string, 0, len(mutedTags))
+		for tag := range mutedTags {
+			muted = append(muted, tag)
+		}
+		query = query.Where("id NOT IN (SELECT project_id FROM project_tags WHERE LOWER(tag_name) IN ?)", muted)
+	}
+
 	err = query.Find(&projects).Error
 	return projects, err
 }
@@ -167,61 +230,94 @@ func (s *RecommendationService) calculateRecommendationScores(
 	userID int64,
 	projects []models.Project,
 	userPreferences map[string]float64,
+	preferredStatuses map[string]bool,
 	userInteractions []models.UserInteraction,
+	params RankingParams,
 ) []RecommendationScore {
 	var recommendations []RecommendationScore
 
+	scoring := ScoringContext{
+		TagPreferences:    userPreferences,
+		PreferredStatuses: preferredStatuses,
+		Now:               time.Now(),
+	}
+
 	for _, project := range projects {
-		score := 0.0
-		reasons := []string{}
-
-		// 1. 标签匹配分数 (40%)
-		tagScore := s.calculateTagScore(project.Tags, userPreferences)
-		score += tagScore * 0.4
-		if tagScore > 0 {
-			reasons = append(reasons, "标签匹配")
-		}
+		similarityScore := s.calculateUserSimilarityScore(userID, project.UserID)
+		recommendations = append(recommendations, ScoreProject(project, scoring, similarityScore, params))
+	}
 
-		// 2. 热度分数 (30%)
-		popularityScore := s.calculatePopularityScore(project)
-		score += popularityScore * 0.3
-		if popularityScore > 0.5 {
-			reasons = append(reasons, "热门项目")
-		}
+	return recommendations
+}
 
-		// 3. 新鲜度分数 (20%)
-		freshnessScore := s.calculateFreshnessScore(project.CreatedAt)
-		score += freshnessScore * 0.2
-		if freshnessScore > 0.7 {
-			reasons = append(reasons, "最新项目")
-		}
+// ScoringContext 为单个用户打分所需的上下文
+type ScoringContext struct {
+	TagPreferences    map[string]float64
+	PreferredStatuses map[string]bool
+	Now               time.Time
+}
 
-		// 4. 用户相似度分数 (10%)
-		similarityScore := s.calculateUserSimilarityScore(userID, project.UserID)
-		score += similarityScore * 0.1
-		if similarityScore > 0.5 {
-			reasons = append(reasons, "相似用户推荐")
-		}
+// ScoreProject 按排序参数计算单个项目的推荐分数，不访问数据库，供在线推荐和离线评估共用
+func ScoreProject(project models.Project, scoring ScoringContext, similarityScore float64, params RankingParams) RecommendationScore {
+	score := 0.0
+	reasons := []string{}
 
-		recommendations = append(recommendations, RecommendationScore{
-			ProjectID: project.ID,
-			Score:     score,
-			Reason:    fmt.Sprintf("%.1f分 - %s", score, fmt.Sprintf("%v", reasons)),
-		})
+	// 1. 标签匹配分数 (40%)
+	tagScore := calculateTagScore(project.Tags, scoring.TagPreferences)
+	score += tagScore * params.TagWeight
+	if tagScore > 0 {
+		reasons = append(reasons, "标签匹配")
 	}
 
-	return recommendations
+	// 2. 热度分数 (30%)
+	popularityScore := calculatePopularityScore(project)
+	score += popularityScore * params.PopularityWeight
+	if popularityScore > 0.5 {
+		reasons = append(reasons, "热门项目")
+	}
+
+	// 3. 新鲜度分数 (20%)
+	freshnessScore := calculateFreshnessScore(project.CreatedAt, scoring.Now)
+	score += freshnessScore * params.FreshnessWeight
+	if freshnessScore > 0.7 {
+		reasons = append(reasons, "最新项目")
+	}
+
+	// 4. 用户相似度分数 (10%)
+	score += similarityScore * params.SimilarityWeight
+	if similarityScore > 0.5 {
+		reasons = append(reasons, "相似用户推荐")
+	}
+
+	// 5. 偏好阶段加成
+	if scoring.PreferredStatuses[project.Status] {
+		score += params.StatusBonus
+		reasons = append(reasons, "偏好阶段")
+	}
+
+	tags := make([]string, 0, len(project.Tags))
+	for _, tag := range project.Tags {
+		tags = append(tags, tag.TagName)
+	}
+
+	return RecommendationScore{
+		ProjectID: project.ID,
+		Score:     score,
+		Reason:    fmt.Sprintf("%.1f分 - %s", score, fmt.Sprintf("%v", reasons)),
+		CreatorID: project.UserID,
+		Tags:      tags,
+	}
 }
 
 // calculateTagScore 计算标签匹配分数
-func (s *RecommendationService) calculateTagScore(tags []models.ProjectTag, userPreferences map[string]float64) float64 {
+func calculateTagScore(tags []models.ProjectTag, userPreferences map[string]float64) float64 {
 	if len(tags) == 0 {
 		return 0
 	}
 
 	totalScore := 0.0
 	for _, tag := range tags {
-		if score, exists := userPreferences[tag.TagName]; exists {
+		if score, exists := userPreferences[tagKey(tag.TagName)]; exists {
 			totalScore += score
 		}
 	}
@@ -230,7 +326,7 @@ func (s *RecommendationService) calculateTagScore(tags []models.ProjectTag, user
 }
 
 // calculatePopularityScore 计算热度分数
-func (s *RecommendationService) calculatePopularityScore(project models.Project) float64 {
+func calculatePopularityScore(project models.Project) float64 {
 	totalInteractions := project.LikeCount + project.DislikeCount + project.SuperLikeCount + project.SkipCount
 	if totalInteractions == 0 {
 		return 0
@@ -247,8 +343,8 @@ func (s *RecommendationService) calculatePopularityScore(project models.Project)
 }
 
 // calculateFreshnessScore 计算新鲜度分数
-func (s *RecommendationService) calculateFreshnessScore(createdAt time.Time) float64 {
-	daysSinceCreated := time.Since(createdAt).Hours() / 24
+func calculateFreshnessScore(createdAt, now time.Time) float64 {
+	daysSinceCreated := now.Sub(createdAt).Hours() / 24
 
 	// 使用指数衰减函数
 	return math.Exp(-daysSinceCreated / 30) // 30天半衰期
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"devswipe-backend/internal/config"
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/pkg/auth"
+	"devswipe-backend/pkg/cache"
+)
+
+// 审计日志中的会话事件
+const (
+	AuditSessionRevoke    = "session_revoke"
+	AuditSessionRevokeAll = "session_revoke_all"
+)
+
+// sessionCacheTTL 有效会话在Redis中的缓存时间，过期后回源数据库并更新最近活跃时间
+const sessionCacheTTL = time.Minute
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session expired or revoked")
+)
+
+type SessionService struct {
+	sessionRepo *repositories.SessionRepository
+	auditRepo   *repositories.AuditRepository
+	cache       *cache.CacheManager
+}
+
+func NewSessionService() *SessionService {
+	return &SessionService{
+		sessionRepo: repositories.NewSessionRepository(),
+		auditRepo:   repositories.NewAuditRepository(),
+		cache:       cache.NewCacheManager(),
+	}
+}
+
+// SessionView 会话列表中的一项，Current 表示发起请求的会话
+type SessionView struct {
+	models.UserSession
+	Current bool `json:"current"`
+}
+
+// Create 为登录成功的用户创建会话并签发JWT
+func (s *SessionService) Create(user *models.User, ip, userAgent string) (string, error) {
+	tokenID, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	session := &models.UserSession{
+		UserID:     user.ID,
+		TokenID:    tokenID,
+		Device:     describeDevice(userAgent),
+		UserAgent:  userAgent,
+		IP:         ip,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(time.Duration(config.AppConfig.JWT.ExpiresIn) * time.Hour),
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return "", err
+	}
+
+	return auth.GenerateToken(user.ID, user.Username, user.Email, user.Role, tokenID)
+}
+
+// Validate 校验JWT对应的会话仍然有效，由 AuthMiddleware 在每个请求调用
+func (s *SessionService) Validate(userID int64, tokenID, ip string) error {
+	if tokenID == "" {
+		// 引入会话之前签发的令牌没有 jti，需要重新登录
+		return ErrSessionRevoked
+	}
+
+	ctx := context.Background()
+	if cached, err := s.cache.Exists(ctx, sessionCacheKey(tokenID)); err == nil && cached {
+		return nil
+	}
+
+	session, err := s.sessionRepo.GetByTokenID(tokenID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID || session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return ErrSessionRevoked
+	}
+
+	if err := s.sessionRepo.Touch(session.ID, ip); err != nil {
+		log.Printf("Failed to record session activity: %v", err)
+	}
+	s.cache.Set(ctx, sessionCacheKey(tokenID), session.ID, sessionCacheTTL)
+	return nil
+}
+
+// List 获取用户的有效会话，currentTokenID 为发起请求的会话
+func (s *SessionService) List(userID int64, currentTokenID string) ([]SessionView, error) {
+	sessions, err := s.sessionRepo.GetActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]SessionView, 0, len(sessions))
+	for _, session := range sessions {
+		views = append(views, SessionView{
+			UserSession: session,
+			Current:     session.TokenID == currentTokenID,
+		})
+	}
+	return views, nil
+}
+
+// Revoke 撤销一个会话，该会话的JWT立即失效
+func (s *SessionService) Revoke(userID, sessionID int64, ip string) error {
+	tokenID, err := s.sessionRepo.Revoke(userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if tokenID == "" {
+		return ErrSessionNotFound
+	}
+
+	s.evict(tokenID)
+	recordAudit(s.auditRepo, &userID, AuditSessionRevoke, ip, fmt.Sprintf("session %d", sessionID))
+	return nil
+}
+
+// RevokeAll 退出所有设备，exceptTokenID 不为空时保留当前会话。返回撤销的会话数
+func (s *SessionService) RevokeAll(userID int64, exceptTokenID, ip string) (int, error) {
+	tokenIDs, err := s.sessionRepo.RevokeAll(userID, exceptTokenID)
+	if err != nil {
+		return 0, err
+	}
+
+	s.evict(tokenIDs...)
+	recordAudit(s.auditRepo, &userID, AuditSessionRevokeAll, ip, fmt.Sprintf("revoked=%d", len(tokenIDs)))
+	return len(tokenIDs), nil
+}
+
+func (s *SessionService) evict(tokenIDs ...string) {
+	if len(tokenIDs) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(tokenIDs))
+	for _, tokenID := range tokenIDs {
+		keys = append(keys, sessionCacheKey(tokenID))
+	}
+	if err := s.cache.DeleteKeys(context.Background(), keys...); err != nil {
+		log.Printf("Failed to evict revoked sessions: %v", err)
+	}
+}
+
+// describeDevice 从 User-Agent 粗略识别浏览器和操作系统，用于会话列表展示
+func describeDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "Unknown device"
+	}
+
+	for _, client := range []string{"curl", "postman", "okhttp", "python-requests", "go-http-client"} {
+		if strings.Contains(ua, client) {
+			return client
+		}
+	}
+
+	browser := "Browser"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	}
+
+	os := ""
+	switch {
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"):
+		os = "iOS"
+	case strings.Contains(ua, "android"):
+		os = "Android"
+	case strings.Contains(ua, "mac os"):
+		os = "macOS"
+	case strings.Contains(ua, "windows"):
+		os = "Windows"
+	case strings.Contains(ua, "linux"):
+		os = "Linux"
+	}
+
+	if os == "" {
+		return browser
+	}
+	return browser + " on " + os
+}
+
+func sessionCacheKey(tokenID string) string {
+	return fmt.Sprintf("user_session:%s", tokenID)
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"devswipe-backend/internal/config"
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/pkg/auth"
+	"devswipe-backend/pkg/cache"
+)
+
+const (
+	// signingKeyReloadInterval 各实例从数据库重新加载密钥环的间隔
+	signingKeyReloadInterval = time.Minute
+	// signingKeyActivationDelay 新密钥先发布用于校验，延迟一段时间后才开始签名，
+	// 保证其它实例在此之前已经加载了它
+	signingKeyActivationDelay = 3 * signingKeyReloadInterval
+	signingKeyLockKey         = "jwt_signing_keys:lock"
+	signingKeyInitRetries     = 10
+)
+
+// SigningKeyService 管理 RS256、EdDSA 签名密钥：持久化、定期轮换，并同步到 pkg/auth 的密钥环
+type SigningKeyService struct {
+	keyRepo *repositories.SigningKeyRepository
+	cache   *cache.CacheManager
+}
+
+func NewSigningKeyService() *SigningKeyService {
+	return &SigningKeyService{
+		keyRepo: repositories.NewSigningKeyRepository(),
+		cache:   cache.NewCacheManager(),
+	}
+}
+
+// Init 启动时加载密钥环，数据库中还没有可用密钥时创建第一个。HS256 不需要数据库中的密钥
+func (s *SigningKeyService) Init(ctx context.Context) error {
+	algorithm := config.AppConfig.JWT.Algorithm
+	switch algorithm {
+	case auth.AlgHS256:
+		return nil
+	case auth.AlgRS256, auth.AlgEdDSA:
+	default:
+		return fmt.Errorf("%w: %s", auth.ErrUnsupportedAlgorithm, algorithm)
+	}
+
+	for i := 0; i < signingKeyInitRetries; i++ {
+		if err := s.Reload(); err == nil {
+			return nil
+		}
+
+		// 多实例同时启动时只由一个实例创建密钥，其它实例等待后重新加载
+		if ok, err := s.cache.AcquireLock(ctx, signingKeyLockKey, signingKeyReloadInterval/2); err == nil && ok {
+			if err := s.rotateIfDue(time.Now()); err != nil {
+				return err
+			}
+			continue
+		}
+		time.Sleep(time.Second)
+	}
+	return s.Reload()
+}
+
+// StartScheduler 定时检查是否需要轮换并重新加载密钥环，ctx 取消时退出
+func (s *SigningKeyService) StartScheduler(ctx context.Context) {
+	if config.AppConfig.JWT.Algorithm == auth.AlgHS256 {
+		return
+	}
+
+	ticker := time.NewTicker(signingKeyReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if ok, err := s.cache.AcquireLock(ctx, signingKeyLockKey, signingKeyReloadInterval/2); err == nil && ok {
+			if err := s.rotateIfDue(time.Now()); err != nil {
+				log.Printf("Failed to rotate signing keys: %v", err)
+			}
+		}
+		if err := s.Reload(); err != nil {
+			log.Printf("Failed to reload signing keys: %v", err)
+		}
+	}
+}
+
+// Reload 从数据库加载仍有效的密钥：最新的已生效密钥用于签名，其余只用于校验
+func (s *SigningKeyService) Reload() error {
+	now := time.Now()
+	stored, err := s.keyRepo.GetUsable(now)
+	if err != nil {
+		return err
+	}
+
+	var signing *auth.Key
+	verification := make([]*auth.Key, 0, len(stored))
+	for _, record := range stored {
+		key, err := auth.ParsePrivateKeyPEM(record.Algorithm, record.PrivateKey)
+		if err != nil {
+			log.Printf("Skipping invalid signing key %s: %v", record.Kid, err)
+			continue
+		}
+		if signing == nil && !record.ActivatesAt.After(now) {
+			signing = key
+			continue
+		}
+		verification = append(verification, key)
+	}
+
+	if signing == nil {
+		return auth.ErrNoSigningKey
+	}
+	auth.SetKeys(signing, verification)
+	return nil
+}
+
+// rotateIfDue 最新的密钥超过轮换周期或算法配置变化时，生成新的密钥
+func (s *SigningKeyService) rotateIfDue(now time.Time) error {
+	stored, err := s.keyRepo.GetUsable(now)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.AppConfig.JWT
+	interval := time.Duration(max(cfg.RotationInterval, 1)) * 24 * time.Hour
+	activatesAt := now.Add(signingKeyActivationDelay)
+
+	if len(stored) > 0 {
+		latest := stored[0]
+		if latest.ActivatesAt.After(now) {
+			// 已有等待生效的新密钥
+			return nil
+		}
+		if latest.Algorithm == cfg.Algorithm && now.Sub(latest.ActivatesAt) < interval {
+			return nil
+		}
+	} else {
+		// 还没有任何密钥时没有需要兼容的旧令牌，立即生效
+		activatesAt = now
+	}
+
+	key, err := auth.GenerateKey(cfg.Algorithm)
+	if err != nil {
+		return err
+	}
+	privatePEM, err := key.PrivateKeyPEM()
+	if err != nil {
+		return err
+	}
+
+	record := &models.SigningKey{
+		Kid:         key.ID,
+		Algorithm:   key.Algorithm,
+		PrivateKey:  privatePEM,
+		ActivatesAt: activatesAt,
+	}
+	if err := s.keyRepo.Create(record); err != nil {
+		return err
+	}
+
+	// 旧密钥在新密钥生效前仍会签名，用它签发的最后一批令牌过期后才停止校验
+	tokenLifetime := time.Duration(cfg.ExpiresIn) * time.Hour
+	return s.keyRepo.ExpireOthers(record.ID, activatesAt.Add(tokenLifetime+signingKeyReloadInterval))
+}
@@ -0,0 +1,580 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/pkg/cache"
+
+	"gorm.io/gorm"
+)
+
+// 标签类型
+const (
+	TagTypeTech      = "tech"
+	TagTypeDomain    = "domain"
+	TagTypeFunction  = "function"
+	TagTypeStage     = "stage"
+	TagTypeHackathon = "hackathon"
+)
+
+// 审计日志中的标签维护事件
+const (
+	AuditTagUpdate        = "tag_update"
+	AuditTagSynonymAdd    = "tag_synonym_add"
+	AuditTagSynonymRemove = "tag_synonym_remove"
+	AuditTagMerge         = "tag_merge"
+)
+
+const maxTagLength = 50
+
+const (
+	tagSuggestCacheTTL  = 5 * time.Minute
+	tagKeyCacheTTL      = 10 * time.Minute
+	popularTagsCacheTTL = 10 * time.Minute
+	tagPageRelatedLimit = 10
+	tagPageTopProjects  = 10
+)
+
+var (
+	ErrTagNotFound        = errors.New("tag not found")
+	ErrTagTooLong         = fmt.Errorf("tag must be at most %d characters", maxTagLength)
+	ErrEmptyTagName       = errors.New("tag name cannot be empty")
+	ErrTagConflict        = errors.New("tag name or synonym is already used by another tag")
+	ErrTagSynonymNotFound = errors.New("synonym not found")
+	ErrMergeSameTag       = errors.New("cannot merge a tag into itself")
+	ErrInvalidTagType     = errors.New("invalid tag type, must be one of tech, domain, function, stage, hackathon")
+)
+
+// 非技术类标签的分类词表，键为小写写法。技术类标签使用 techStackNames
+var (
+	stageTags = map[string]bool{
+		"idea": true, "concept": true, "prototype": true, "mvp": true, "demo": true,
+		"alpha": true, "beta": true, "launched": true, "production": true,
+	}
+	domainTags = map[string]bool{
+		"web app": true, "mobile app": true, "developer tools": true, "devtools": true,
+		"fintech": true, "finance": true, "education": true, "edtech": true,
+		"healthcare": true, "health": true, "gaming": true, "games": true,
+		"e-commerce": true, "ecommerce": true, "social": true, "productivity": true,
+		"travel": true, "music": true, "sports": true, "climate": true,
+		"sustainability": true, "iot": true, "security": true, "open source": true,
+	}
+	functionTags = map[string]bool{
+		"real-time": true, "realtime": true, "chat": true, "chatbot": true,
+		"dashboard": true, "analytics": true, "search": true, "recommendation": true,
+		"authentication": true, "payments": true, "notifications": true,
+		"visualization": true, "data visualization": true, "collaboration": true,
+		"automation": true, "cli": true, "code review": true, "monitoring": true,
+	}
+)
+
+// TagService 维护标签词表：写入项目标签时统一大小写和写法、自动分类，并为版主提供改名、同义词和合并操作
+type TagService struct {
+	tagRepo     *repositories.TagRepository
+	projectRepo *repositories.ProjectRepository
+	auditRepo   *repositories.AuditRepository
+	cache       *cache.CacheManager
+}
+
+func NewTagService() *TagService {
+	return &TagService{
+		tagRepo:     repositories.NewTagRepository(),
+		projectRepo: repositories.NewProjectRepository(),
+		auditRepo:   repositories.NewAuditRepository(),
+		cache:       cache.NewCacheManager(),
+	}
+}
+
+type UpdateTagRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=50"`
+	TagType     *string `json:"tag_type" binding:"omitempty,oneof=tech domain function stage hackathon"`
+	Description *string `json:"description"`
+}
+
+type TagSynonymRequest struct {
+	Synonym string `json:"synonym" binding:"required,max=50"`
+}
+
+type MergeTagsRequest struct {
+	TargetID int64 `json:"target_id" binding:"required"`
+}
+
+// TagPage 标签详情页：标签本身、公开项目数、相关标签和该标签下互动最多的项目
+type TagPage struct {
+	Tag          *models.Tag             `json:"tag"`
+	ProjectCount int64                   `json:"project_count"`
+	Related      []repositories.TagCount `json:"related"`
+	TopProjects  []models.Project        `json:"top_projects"`
+}
+
+// Resolve 在事务 tx 中将用户输入的标签转换为规范标签，不存在时自动创建。结果去重并保持输入顺序。
+// 新标签与项目在同一个事务中写入，项目创建失败时不会留下孤立的标签
+func (s *TagService) Resolve(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	repo := s.tagRepo.WithTx(tx)
+	seen := make(map[int64]bool)
+	tags := []models.Tag{}
+	for _, name := range names {
+		tag, err := s.resolve(repo, name, TagTypeTech)
+		if err != nil {
+			return nil, err
+		}
+		if tag == nil || seen[tag.ID] {
+			continue
+		}
+		seen[tag.ID] = true
+		tags = append(tags, *tag)
+	}
+	return tags, nil
+}
+
+// resolve 通过 repo 查找或创建单个标签，输入为空时返回 nil。defaultType 为无法自动分类时使用的类型
+func (s *TagService) resolve(repo *repositories.TagRepository, name, defaultType string) (*models.Tag, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(name) > maxTagLength {
+		return nil, ErrTagTooLong
+	}
+
+	key := tagKey(name)
+	tag, err := repo.FindByKey(key)
+	if err != nil || tag != nil {
+		return tag, err
+	}
+
+	canonical, slug, alias := canonicalTagName(name)
+	if !alias {
+		return s.create(repo, canonical, slug, defaultType)
+	}
+
+	// 内置词表中的别名归并到规范写法，并记录为同义词
+	if tag, err = repo.FindByKey(slug); err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		if tag, err = s.create(repo, canonical, slug, defaultType); err != nil {
+			return nil, err
+		}
+	}
+	if err := repo.AddSynonym(tag.ID, key); err != nil {
+		log.Printf("Failed to add synonym %q for tag %d: %v", key, tag.ID, err)
+	}
+	return tag, nil
+}
+
+// canonicalTagName 词表中还没有该标签时使用的规范写法和 Slug：大小写不同的常见技术使用规范写法（如 react -> React），
+// 其它标签保留输入的写法。alias 表示输入是内置词表中的别名（如 ReactJS），需要记录为同义词
+func canonicalTagName(name string) (canonical, slug string, alias bool) {
+	name = strings.Join(strings.Fields(name), " ")
+	key := strings.ToLower(name)
+	if known, ok := techStackNames[key]; ok {
+		slug = strings.ToLower(known)
+		return known, slug, slug != key
+	}
+	return name, key, false
+}
+
+// create 通过 repo 创建标签。并发创建同一个标签时唯一索引冲突，改为读取已创建的标签。
+// 对方的标签已经提交，在事务外读取，避免事务的一致性快照看不到它
+func (s *TagService) create(repo *repositories.TagRepository, name, slug, defaultType string) (*models.Tag, error) {
+	tag := &models.Tag{
+		Name:    name,
+		Slug:    slug,
+		TagType: classifyTag(slug, defaultType),
+	}
+	if err := repo.Create(tag); err != nil {
+		existing, findErr := s.tagRepo.FindByKey(slug)
+		if findErr != nil || existing == nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+	return tag, nil
+}
+
+// classifyTag 按词表推断标签类型，无法判断时返回 defaultType
+func classifyTag(key, defaultType string) string {
+	switch {
+	case strings.Contains(key, "hackathon") || strings.Contains(key, "game jam") || strings.HasSuffix(key, " jam"):
+		return TagTypeHackathon
+	case stageTags[key]:
+		return TagTypeStage
+	case techStackNames[key] != "":
+		return TagTypeTech
+	case domainTags[key]:
+		return TagTypeDomain
+	case functionTags[key]:
+		return TagTypeFunction
+	}
+	return defaultType
+}
+
+// tagKey 标签的查找键：小写并合并多余空白
+func tagKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// validTagType 是否为已定义的标签类型
+func validTagType(tagType string) bool {
+	switch tagType {
+	case TagTypeTech, TagTypeDomain, TagTypeFunction, TagTypeStage, TagTypeHackathon:
+		return true
+	}
+	return false
+}
+
+// newProjectTags 为项目生成指向规范标签的项目标签
+func newProjectTags(projectID int64, tags []models.Tag) []models.ProjectTag {
+	projectTags := make([]models.ProjectTag, 0, len(tags))
+	for _, tag := range tags {
+		projectTags = append(projectTags, models.ProjectTag{
+			ProjectID: projectID,
+			TagID:     tag.ID,
+			TagName:   tag.Name,
+			TagType:   tag.TagType,
+		})
+	}
+	return projectTags
+}
+
+// BackfillProjectTags 将引入标签词表之前写入的项目标签关联到规范标签，返回处理的标签名数量。
+// 历史数据中已有的非 tech 类型在无法自动分类时保留
+func (s *TagService) BackfillProjectTags() (int, error) {
+	unlinked, err := s.tagRepo.GetUnlinkedTags()
+	if err != nil {
+		return 0, err
+	}
+
+	linked := 0
+	for _, item := range unlinked {
+		defaultType := item.TagType
+		if !validTagType(defaultType) {
+			defaultType = TagTypeTech
+		}
+
+		tag, err := s.resolve(s.tagRepo, item.TagName, defaultType)
+		if err != nil || tag == nil {
+			log.Printf("Failed to resolve tag %q: %v", item.TagName, err)
+			continue
+		}
+		if err := s.tagRepo.LinkProjectTags(item.TagName, tag); err != nil {
+			return linked, err
+		}
+		linked++
+	}
+	return linked, nil
+}
+
+func (s *TagService) ListTags(keyword, tagType string, limit, offset int) ([]models.Tag, int64, error) {
+	return s.tagRepo.List(strings.ToLower(strings.TrimSpace(keyword)), tagType, limit, offset)
+}
+
+func (s *TagService) GetTag(tagID int64) (*models.Tag, error) {
+	tag, err := s.tagRepo.GetByID(tagID)
+	if err != nil {
+		return nil, ErrTagNotFound
+	}
+	return tag, nil
+}
+
+// UpdateTag 修改标签的规范写法、类型或描述。改名后旧写法保留为同义词
+func (s *TagService) UpdateTag(actorID, tagID int64, req *UpdateTagRequest, ip string) (*models.Tag, error) {
+	tag, err := s.GetTag(tagID)
+	if err != nil {
+		return nil, err
+	}
+	oldSlug := tag.Slug
+
+	if req.Name != nil {
+		name := strings.Join(strings.Fields(*req.Name), " ")
+		slug := strings.ToLower(name)
+		if name == "" {
+			return nil, ErrEmptyTagName
+		}
+		if slug != oldSlug {
+			inUse, err := s.tagRepo.KeyInUse(slug, tag.ID)
+			if err != nil {
+				return nil, err
+			}
+			if inUse {
+				return nil, ErrTagConflict
+			}
+		}
+		tag.Name = name
+		tag.Slug = slug
+	}
+	if req.TagType != nil {
+		tag.TagType = *req.TagType
+	}
+	if req.Description != nil {
+		tag.Description = strings.TrimSpace(*req.Description)
+	}
+
+	if tag.Slug != oldSlug {
+		// 新写法原来是该标签的同义词时先删除，避免与 Slug 重复
+		if _, err := s.tagRepo.RemoveSynonym(tag.ID, tag.Slug); err != nil {
+			return nil, err
+		}
+	}
+	synonyms := tag.Synonyms
+	tag.Synonyms = nil
+	if err := s.tagRepo.Update(tag); err != nil {
+		return nil, err
+	}
+	tag.Synonyms = synonyms
+	if tag.Slug != oldSlug {
+		if err := s.tagRepo.AddSynonym(tag.ID, oldSlug); err != nil {
+			log.Printf("Failed to keep old name %q of tag %d as synonym: %v", oldSlug, tag.ID, err)
+		}
+	}
+
+	s.invalidate()
+	recordAudit(s.auditRepo, &actorID, AuditTagUpdate, ip,
+		fmt.Sprintf("tag %d %q type=%s (was %q)", tag.ID, tag.Name, tag.TagType, oldSlug))
+	return s.GetTag(tag.ID)
+}
+
+// AddSynonym 为标签添加同义词，之后以该写法输入的标签都会归并到这个标签
+func (s *TagService) AddSynonym(actorID, tagID int64, synonym, ip string) error {
+	if _, err := s.GetTag(tagID); err != nil {
+		return err
+	}
+
+	key := tagKey(synonym)
+	if key == "" {
+		return ErrEmptyTagName
+	}
+	existing, err := s.tagRepo.FindByKey(key)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrTagConflict
+	}
+
+	if err := s.tagRepo.AddSynonym(tagID, key); err != nil {
+		return err
+	}
+	s.invalidate()
+	recordAudit(s.auditRepo, &actorID, AuditTagSynonymAdd, ip, fmt.Sprintf("tag %d synonym %q", tagID, key))
+	return nil
+}
+
+func (s *TagService) RemoveSynonym(actorID, tagID int64, synonym, ip string) error {
+	key := tagKey(synonym)
+	found, err := s.tagRepo.RemoveSynonym(tagID, key)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrTagSynonymNotFound
+	}
+
+	s.invalidate()
+	recordAudit(s.auditRepo, &actorID, AuditTagSynonymRemove, ip, fmt.Sprintf("tag %d synonym %q", tagID, key))
+	return nil
+}
+
+// MergeTags 将 sourceID 合并到 targetID，source 的项目标签和写法全部归入 target
+func (s *TagService) MergeTags(actorID, sourceID, targetID int64, ip string) (*models.Tag, error) {
+	if sourceID == targetID {
+		return nil, ErrMergeSameTag
+	}
+	source, err := s.GetTag(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.GetTag(targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tagRepo.Merge(source, target); err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	recordAudit(s.auditRepo, &actorID, AuditTagMerge, ip,
+		fmt.Sprintf("tag %d %q merged into %d %q", source.ID, source.Name, target.ID, target.Name))
+	return s.GetTag(target.ID)
+}
+
+// Suggest 标签自动补全：按前缀匹配规范写法和同义词，使用多的标签排在前面。前缀为空时返回热门标签
+func (s *TagService) Suggest(prefix string, limit int) ([]repositories.TagCount, error) {
+	key := tagKey(prefix)
+	if key == "" {
+		return s.Popular("", limit)
+	}
+
+	ctx := context.Background()
+	cacheKey := fmt.Sprintf("tag_suggest:%s:%d", key, limit)
+	var tags []repositories.TagCount
+	if err := s.cache.Get(ctx, cacheKey, &tags); err == nil {
+		return tags, nil
+	}
+
+	tags, err := s.tagRepo.SearchByPrefix(key, limit)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(ctx, cacheKey, tags, tagSuggestCacheTTL)
+	return tags, nil
+}
+
+// Popular 获取公开项目数最多的标签，tagType 为空时不限类型
+func (s *TagService) Popular(tagType string, limit int) ([]repositories.TagCount, error) {
+	if tagType != "" && !validTagType(tagType) {
+		return nil, ErrInvalidTagType
+	}
+
+	ctx := context.Background()
+	cacheKey := fmt.Sprintf("popular_tags:%s:%d", tagType, limit)
+	var tags []repositories.TagCount
+	if err := s.cache.Get(ctx, cacheKey, &tags); err == nil {
+		return tags, nil
+	}
+
+	tags, err := s.tagRepo.GetPopular(tagType, limit)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(ctx, cacheKey, tags, popularTagsCacheTTL)
+	return tags, nil
+}
+
+// GetTagPage 按名称（任意大小写或同义词）获取标签详情，viewerID 为0表示未登录
+func (s *TagService) GetTagPage(viewerID int64, name string) (*TagPage, error) {
+	tag, err := s.tagRepo.FindByKey(tagKey(name))
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, ErrTagNotFound
+	}
+	if tag, err = s.GetTag(tag.ID); err != nil {
+		return nil, err
+	}
+
+	page := &TagPage{Tag: tag}
+	if page.ProjectCount, err = s.tagRepo.CountProjects(tag.ID); err != nil {
+		return nil, err
+	}
+	if page.Related, err = s.tagRepo.GetRelated(tag.ID, tagPageRelatedLimit); err != nil {
+		return nil, err
+	}
+	if page.TopProjects, err = s.projectRepo.GetTopProjectsByTag(tag.ID, tagPageTopProjects); err != nil {
+		return nil, err
+	}
+
+	hidden, err := NewBlockService().HiddenUsers(viewerID)
+	if err != nil {
+		return nil, err
+	}
+	page.TopProjects = FilterHiddenAuthors(page.TopProjects, hidden)
+	return page, nil
+}
+
+// LookupIDs 查找已有标签的ID，不存在的标签忽略，不会创建新标签
+func (s *TagService) LookupIDs(names []string) ([]int64, error) {
+	ids := []int64{}
+	for _, name := range names {
+		key := tagKey(name)
+		if key == "" {
+			continue
+		}
+		tag, err := s.tagRepo.FindByKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if tag != nil {
+			ids = append(ids, tag.ID)
+		}
+	}
+	return ids, nil
+}
+
+// CanonicalKey 将标签的任意写法归并为规范标签的 Slug。偏好画像、屏蔽标签和热榜都以它为键，
+// 标签改名或合并后，旧写法仍然对应到同一个键
+func (s *TagService) CanonicalKey(name string) (string, error) {
+	canonical, err := s.CanonicalKeys([]string{name})
+	if err != nil {
+		return "", err
+	}
+	return canonical[tagKey(name)], nil
+}
+
+// CanonicalKeys 批量归并标签写法，返回以查找键（tagKey）为键的规范键，空项忽略。
+// 先用一次 MGET 读取缓存，未命中的写法再用一次批量查询解析
+func (s *TagService) CanonicalKeys(names []string) (map[string]string, error) {
+	canonical := make(map[string]string, len(names))
+	cacheKeys := make([]string, 0, len(names))
+	for _, name := range names {
+		key := tagKey(name)
+		if key == "" {
+			continue
+		}
+		if _, seen := canonical[key]; !seen {
+			canonical[key] = ""
+			cacheKeys = append(cacheKeys, "tag_key:"+key)
+		}
+	}
+	if len(cacheKeys) == 0 {
+		return canonical, nil
+	}
+
+	ctx := context.Background()
+	// 缓存不可用时全部按未命中处理
+	cached, _ := s.cache.GetMulti(ctx, cacheKeys)
+
+	var misses []string
+	for _, cacheKey := range cacheKeys {
+		key := strings.TrimPrefix(cacheKey, "tag_key:")
+		var value string
+		if data, ok := cached[cacheKey]; ok && json.Unmarshal([]byte(data), &value) == nil {
+			canonical[key] = value
+			continue
+		}
+		misses = append(misses, key)
+	}
+	if len(misses) == 0 {
+		return canonical, nil
+	}
+
+	tags, err := s.tagRepo.FindByKeys(misses)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range misses {
+		canonical[key] = canonicalTagKey(key, tags[key])
+		s.cache.Set(ctx, "tag_key:"+key, canonical[key], tagKeyCacheTTL)
+	}
+	return canonical, nil
+}
+
+// canonicalTagKey 查找键对应的规范键：词表中已有的标签取其 Slug，
+// 还没有的标签取 resolve 创建它时会使用的 Slug，内置别名归并到规范写法
+func canonicalTagKey(key string, tag *models.Tag) string {
+	if tag != nil {
+		return tag.Slug
+	}
+	_, slug, _ := canonicalTagName(key)
+	return slug
+}
+
+// invalidate 标签词表变化后清除自动补全、热门标签和规范键缓存
+func (s *TagService) invalidate() {
+	ctx := context.Background()
+	s.cache.DeleteByPattern(ctx, "tag_suggest:*")
+	s.cache.DeleteByPattern(ctx, "popular_tags:*")
+	s.cache.DeleteByPattern(ctx, "tag_key:*")
+}
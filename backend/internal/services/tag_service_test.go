@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"devswipe-backend/internal/models"
+)
+
+func TestClassifyTag(t *testing.T) {
+	tests := []struct {
+		key         string
+		defaultType string
+		want        string
+	}{
+		{"react", TagTypeTech, TagTypeTech},
+		{"k8s", TagTypeDomain, TagTypeTech},
+		{"mvp", TagTypeTech, TagTypeStage},
+		{"fintech", TagTypeTech, TagTypeDomain},
+		{"real-time", TagTypeTech, TagTypeFunction},
+		{"ethglobal hackathon 2024", TagTypeTech, TagTypeHackathon},
+		{"ludum dare game jam", TagTypeTech, TagTypeHackathon},
+		{"web app", TagTypeTech, TagTypeDomain},
+		{"webrtc", TagTypeTech, TagTypeTech},
+		{"something new", TagTypeFunction, TagTypeFunction},
+	}
+
+	for _, tt := range tests {
+		if got := classifyTag(tt.key, tt.defaultType); got != tt.want {
+			t.Errorf("classifyTag(%q, %q) = %q, want %q", tt.key, tt.defaultType, got, tt.want)
+		}
+	}
+}
+
+func TestTagKey(t *testing.T) {
+	tests := map[string]string{
+		"React":                 "react",
+		"  Machine   Learning ": "machine learning",
+		"":                      "",
+		"   ":                   "",
+	}
+
+	for name, want := range tests {
+		if got := tagKey(name); got != want {
+			t.Errorf("tagKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCanonicalTagName(t *testing.T) {
+	tests := []struct {
+		name      string
+		canonical string
+		slug      string
+		alias     bool
+	}{
+		{"react", "React", "react", false},
+		{"  REACT ", "React", "react", false},
+		{"ReactJS", "React", "react", true},
+		{"golang", "Go", "go", true},
+		{"Spring   Boot", "Spring Boot", "spring boot", false},
+		{"My  Side Project", "My Side Project", "my side project", false},
+	}
+
+	for _, tt := range tests {
+		canonical, slug, alias := canonicalTagName(tt.name)
+		if canonical != tt.canonical || slug != tt.slug || alias != tt.alias {
+			t.Errorf("canonicalTagName(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.name, canonical, slug, alias, tt.canonical, tt.slug, tt.alias)
+		}
+	}
+}
+
+func TestCanonicalTagKey(t *testing.T) {
+	// 合并或改名后旧写法是目标标签的同义词，FindByKey 返回目标标签
+	merged := &models.Tag{ID: 2, Name: "Machine Learning", Slug: "machine learning"}
+	if got := canonicalTagKey("ml", merged); got != "machine learning" {
+		t.Errorf("canonicalTagKey(%q, merged) = %q, want %q", "ml", got, "machine learning")
+	}
+
+	// 词表中还没有的标签使用 resolve 创建时的 Slug
+	tests := map[string]string{
+		"reactjs":  "react",
+		"golang":   "go",
+		"web3 dao": "web3 dao",
+	}
+	for key, want := range tests {
+		if got := canonicalTagKey(key, nil); got != want {
+			t.Errorf("canonicalTagKey(%q, nil) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestMergeTagsSameTag(t *testing.T) {
+	if _, err := NewTagService().MergeTags(1, 5, 5, "127.0.0.1"); !errors.Is(err, ErrMergeSameTag) {
+		t.Fatalf("MergeTags(5, 5) error = %v, want ErrMergeSameTag", err)
+	}
+}
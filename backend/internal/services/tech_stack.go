@@ -0,0 +1,95 @@
+package services
+
+import "strings"
+
+// techStackNames 常见技术的规范写法，键为小写的写法或别名。
+// 不在表中的技术保留用户的写法，只去掉多余空白
+var techStackNames = map[string]string{
+	"go":            "Go",
+	"golang":        "Go",
+	"javascript":    "JavaScript",
+	"js":            "JavaScript",
+	"typescript":    "TypeScript",
+	"ts":            "TypeScript",
+	"python":        "Python",
+	"py":            "Python",
+	"java":          "Java",
+	"kotlin":        "Kotlin",
+	"swift":         "Swift",
+	"rust":          "Rust",
+	"c":             "C",
+	"c++":           "C++",
+	"cpp":           "C++",
+	"c#":            "C#",
+	"csharp":        "C#",
+	"php":           "PHP",
+	"ruby":          "Ruby",
+	"dart":          "Dart",
+	"solidity":      "Solidity",
+	"react":         "React",
+	"reactjs":       "React",
+	"react.js":      "React",
+	"react native":  "React Native",
+	"vue":           "Vue",
+	"vuejs":         "Vue",
+	"vue.js":        "Vue",
+	"angular":       "Angular",
+	"svelte":        "Svelte",
+	"next.js":       "Next.js",
+	"nextjs":        "Next.js",
+	"node":          "Node.js",
+	"nodejs":        "Node.js",
+	"node.js":       "Node.js",
+	"flutter":       "Flutter",
+	"django":        "Django",
+	"flask":         "Flask",
+	"fastapi":       "FastAPI",
+	"spring":        "Spring",
+	"spring boot":   "Spring Boot",
+	"rails":         "Ruby on Rails",
+	"ruby on rails": "Ruby on Rails",
+	"gin":           "Gin",
+	"tailwind":      "Tailwind CSS",
+	"tailwindcss":   "Tailwind CSS",
+	"tailwind css":  "Tailwind CSS",
+	"mysql":         "MySQL",
+	"postgres":      "PostgreSQL",
+	"postgresql":    "PostgreSQL",
+	"mongodb":       "MongoDB",
+	"mongo":         "MongoDB",
+	"redis":         "Redis",
+	"sqlite":        "SQLite",
+	"docker":        "Docker",
+	"kubernetes":    "Kubernetes",
+	"k8s":           "Kubernetes",
+	"aws":           "AWS",
+	"gcp":           "GCP",
+	"graphql":       "GraphQL",
+	"tensorflow":    "TensorFlow",
+	"pytorch":       "PyTorch",
+	"web3":          "Web3",
+	"web3.js":       "Web3.js",
+}
+
+// normalizeTechStack 统一技术栈的写法并去重（忽略大小写），保留原有顺序
+func normalizeTechStack(items []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, item := range items {
+		item = strings.Join(strings.Fields(item), " ")
+		if item == "" {
+			continue
+		}
+		if name, ok := techStackNames[strings.ToLower(item)]; ok {
+			item = name
+		}
+
+		key := strings.ToLower(item)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, item)
+	}
+	return result
+}
@@ -0,0 +1,18 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTechStack(t *testing.T) {
+	got := normalizeTechStack([]string{" golang ", "reactjs", "Go", "", "node.js", "Spring   Boot", "Elixir", "elixir"})
+	want := []string{"Go", "React", "Node.js", "Spring Boot", "Elixir"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("normalizeTechStack() = %v, want %v", got, want)
+	}
+
+	if got := normalizeTechStack(nil); got == nil || len(got) != 0 {
+		t.Fatalf("normalizeTechStack(nil) = %#v, want empty slice", got)
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"devswipe-backend/internal/config"
+	"devswipe-backend/internal/models"
+	"devswipe-backend/internal/repositories"
+	"devswipe-backend/pkg/cache"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 热度计算中各类互动的权重
+const (
+	trendingLikeWeight      = 1.0
+	trendingSuperLikeWeight = 3.0
+	trendingCommentWeight   = 2.0
+	trendingViewWeight      = 0.05
+)
+
+const (
+	trendingKeyPrefix    = "trending"
+	trendingRefreshedKey = "trending:refreshed_at"
+	trendingLockKey      = "trending:lock"
+)
+
+// TrendingWindows 支持的热榜时间窗口
+var TrendingWindows = map[string]time.Duration{
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+}
+
+type TrendingService struct {
+	projectRepo *repositories.ProjectRepository
+	tagService  *TagService
+	cache       *cache.CacheManager
+}
+
+func NewTrendingService() *TrendingService {
+	return &TrendingService{
+		projectRepo: repositories.NewProjectRepository(),
+		tagService:  NewTagService(),
+		cache:       cache.NewCacheManager(),
+	}
+}
+
+// StartScheduler 按配置的间隔定时重新计算热榜，ctx 取消时退出
+func (s *TrendingService) StartScheduler(ctx context.Context) {
+	interval := trendingRefreshInterval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// 多实例部署时只需要一个实例计算
+		if ok, err := s.cache.AcquireLock(ctx, trendingLockKey, interval/2); err == nil && ok {
+			if err := s.Refresh(ctx); err != nil {
+				log.Printf("Failed to refresh trending: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh 重新计算所有时间窗口的总榜和标签榜
+func (s *TrendingService) Refresh(ctx context.Context) error {
+	now := time.Now()
+	ttl := 3 * trendingRefreshInterval()
+	// 榜单比刷新标记多保留一段时间，标记过期后正在重新计算时其他请求仍可读取旧榜单
+	rankingTTL := 2 * ttl
+
+	for window, span := range TrendingWindows {
+		since := now.Add(-span)
+
+		activity, err := s.projectRepo.GetActivitySince(since)
+		if err != nil {
+			return err
+		}
+
+		activeIDs := make([]int64, 0, len(activity))
+		for id := range activity {
+			activeIDs = append(activeIDs, id)
+		}
+
+		projects, err := s.projectRepo.GetTrendingCandidates(since, activeIDs)
+		if err != nil {
+			return err
+		}
+
+		overall := make([]redis.Z, 0, len(projects))
+		byTag := make(map[string][]redis.Z)
+		for _, project := range projects {
+			score := HotScore(project, activity[project.ID], now, config.AppConfig.Trending.Gravity)
+			if score <= 0 {
+				continue
+			}
+
+			member := redis.Z{Score: score, Member: project.ID}
+			overall = append(overall, member)
+			for _, tag := range project.Tags {
+				key := tagKey(tag.TagName)
+				if key != "" {
+					byTag[key] = append(byTag[key], member)
+				}
+			}
+		}
+
+		if err := s.cache.ReplaceSortedSet(ctx, trendingKey(window, ""), overall, rankingTTL); err != nil {
+			return err
+		}
+		for tag, members := range byTag {
+			if err := s.cache.ReplaceSortedSet(ctx, trendingKey(window, tag), members, rankingTTL); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.cache.Set(ctx, trendingRefreshedKey, now.Unix(), ttl)
+}
+
+// GetTrending 获取指定窗口的热榜，tag 不为空时返回该标签下的热榜。
+// viewerID 拉黑、静音的作者和拉黑了 viewerID 的作者的项目会被移除，hasMore 按过滤前的榜单判断
+func (s *TrendingService) GetTrending(viewerID int64, window, tag string, limit, offset int) (projects []models.Project, hasMore bool, err error) {
+	if _, ok := TrendingWindows[window]; !ok {
+		return nil, false, fmt.Errorf("unsupported trending window: %s", window)
+	}
+
+	ctx := context.Background()
+
+	// 热榜尚未计算或已过期时，只有抢到锁的请求同步计算，其余请求直接读取旧榜单（首次计算期间为空）
+	if refreshed, err := s.cache.Exists(ctx, trendingRefreshedKey); err != nil || !refreshed {
+		if ok, err := s.cache.AcquireLock(ctx, trendingLockKey, trendingRefreshInterval()/2); err == nil && ok {
+			if err := s.Refresh(ctx); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	// 标签的同义词和改名前的写法查询同一个榜单
+	key, err := s.tagService.CanonicalKey(tag)
+	if err != nil {
+		return nil, false, err
+	}
+
+	members, err := s.cache.GetSortedSetRevRange(ctx, trendingKey(window, key), int64(offset), int64(offset+limit-1))
+	if err != nil {
+		return nil, false, err
+	}
+
+	ids := make([]int64, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	if projects, err = s.projectRepo.GetByIDs(ids); err != nil {
+		return nil, false, err
+	}
+
+	hidden, err := NewBlockService().HiddenUsers(viewerID)
+	if err != nil {
+		return nil, false, err
+	}
+	return FilterHiddenAuthors(projects, hidden), len(members) == limit, nil
+}
+
+// HotScore 类似 Hacker News 的热度公式：互动得分 / (发布小时数 + 2)^gravity
+func HotScore(project models.Project, activity *repositories.ProjectActivity, now time.Time, gravity float64) float64 {
+	points := trendingViewWeight * float64(project.ViewCount)
+	if activity != nil {
+		points += trendingLikeWeight*float64(activity.Likes) +
+			trendingSuperLikeWeight*float64(activity.SuperLikes) +
+			trendingCommentWeight*float64(activity.Comments)
+	}
+
+	ageHours := math.Max(now.Sub(project.CreatedAt).Hours(), 0)
+	return points / math.Pow(ageHours+2, gravity)
+}
+
+// trendingRefreshInterval 热榜的刷新间隔，未配置时为10分钟
+func trendingRefreshInterval() time.Duration {
+	interval := time.Duration(config.AppConfig.Trending.RefreshInterval) * time.Minute
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	return interval
+}
+
+func trendingKey(window, tag string) string {
+	if tag == "" {
+		return fmt.Sprintf("%s:%s", trendingKeyPrefix, window)
+	}
+	return fmt.Sprintf("%s:%s:tag:%s", trendingKeyPrefix, window, tag)
+}
@@ -3,20 +3,38 @@ package services
 import (
 	"devswipe-backend/internal/models"
 	"devswipe-backend/internal/repositories"
-	"devswipe-backend/pkg/auth"
 	"errors"
+	"net/url"
+	"regexp"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// 个人主页的可见范围
+const (
+	ProfileVisibilityPublic    = "public"
+	ProfileVisibilityFollowers = "followers" // 只有关注者能看到完整资料
+	ProfileVisibilityPrivate   = "private"   // 只有本人能看到完整资料，且不出现在用户搜索中
+)
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrProfileRestricted = errors.New("this profile is only visible to its followers or the owner")
+)
+
 type UserService struct {
-	userRepo *repositories.UserRepository
+	userRepo    *repositories.UserRepository
+	projectRepo *repositories.ProjectRepository
+	loginGuard  *LoginGuard
 }
 
 func NewUserService() *UserService {
 	return &UserService{
-		userRepo: repositories.NewUserRepository(),
+		userRepo:    repositories.NewUserRepository(),
+		projectRepo: repositories.NewProjectRepository(),
+		loginGuard:  NewLoginGuard(),
 	}
 }
 
@@ -32,24 +50,36 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type UnlockAccountRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Token string `json:"token" binding:"required"`
+}
+
+// AuthResponse 登录结果。启用两步验证的用户在密码校验通过后只拿到 MFAToken，
+// 需要再调用 /auth/login/mfa 提交验证码换取 Token
 type AuthResponse struct {
-	UserID   int64  `json:"user_id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Token    string `json:"token"`
+	UserID      int64  `json:"user_id"`
+	Username    string `json:"username,omitempty"`
+	Email       string `json:"email,omitempty"`
+	Token       string `json:"token,omitempty"`
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
 }
 
-func (s *UserService) Register(req *RegisterRequest) (*AuthResponse, error) {
+func (s *UserService) Register(req *RegisterRequest, ip, userAgent string) (*AuthResponse, error) {
 	// 检查邮箱是否已存在
 	existingUser, err := s.userRepo.GetByEmail(req.Email)
 	if err == nil && existingUser != nil {
 		return nil, errors.New("email already exists")
 	}
 
-	// 检查用户名是否已存在
+	// 检查用户名是否已存在，其他用户改名前的旧用户名同样不可用
 	existingUser, err = s.userRepo.GetByUsername(req.Username)
 	if err == nil && existingUser != nil {
-		return nil, errors.New("username already exists")
+		return nil, ErrUsernameTaken
+	}
+	if redirect, err := s.userRepo.GetUsernameRedirect(req.Username); err == nil && redirect != nil {
+		return nil, ErrUsernameTaken
 	}
 
 	// 加密密码
@@ -63,15 +93,17 @@ func (s *UserService) Register(req *RegisterRequest) (*AuthResponse, error) {
 		Username:     req.Username,
 		Email:        req.Email,
 		PasswordHash: string(hashedPassword),
-		TechStack:    strings.Join(req.TechStack, ","),
+		TechStack:    strings.Join(normalizeTechStack(req.TechStack), ","),
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, err
 	}
 
-	// 生成JWT token
-	token, err := auth.GenerateToken(user.ID, user.Username, user.Email)
+	NewAccountService().sendVerificationAsync(user.ID)
+
+	// 创建会话并生成JWT token
+	token, err := NewSessionService().Create(user, ip, userAgent)
 	if err != nil {
 		return nil, err
 	}
@@ -84,25 +116,40 @@ func (s *UserService) Register(req *RegisterRequest) (*AuthResponse, error) {
 	}, nil
 }
 
-func (s *UserService) Login(req *LoginRequest) (*AuthResponse, error) {
+func (s *UserService) Login(req *LoginRequest, ip, userAgent string) (*AuthResponse, error) {
+	// 账户或IP处于退避、锁定状态时直接拒绝，不再校验密码
+	if err := s.loginGuard.Check(req.Email, ip); err != nil {
+		return nil, err
+	}
+
 	// 获取用户
 	user, err := s.userRepo.GetByEmail(req.Email)
-	if err != nil {
-		return nil, errors.New("invalid email or password")
-	}
-	// 用户不存在时返回统一的认证错误，避免空指针
-	if user == nil {
+	if err != nil || user == nil {
+		// 用户不存在时同样计入失败次数，并返回统一的认证错误
+		s.loginGuard.RecordFailure(req.Email, ip, nil)
 		return nil, errors.New("invalid email or password")
 	}
 
 	// 验证密码
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
 	if err != nil {
+		s.loginGuard.RecordFailure(req.Email, ip, user)
 		return nil, errors.New("invalid email or password")
 	}
 
-	// 生成JWT token
-	token, err := auth.GenerateToken(user.ID, user.Username, user.Email)
+	s.loginGuard.RecordSuccess(req.Email)
+
+	return loginResponse(user, ip, userAgent)
+}
+
+// LoginMFA 登录的第二步：使用MFA令牌和验证码换取JWT
+func (s *UserService) LoginMFA(req *MFALoginRequest, ip, userAgent string) (*AuthResponse, error) {
+	user, err := NewMFAService().CompleteLogin(req, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := NewSessionService().Create(user, ip, userAgent)
 	if err != nil {
 		return nil, err
 	}
@@ -115,45 +162,413 @@ func (s *UserService) Login(req *LoginRequest) (*AuthResponse, error) {
 	}, nil
 }
 
+// UnlockAccount 使用锁定时发放的令牌解除账户锁定
+func (s *UserService) UnlockAccount(req *UnlockAccountRequest, ip string) error {
+	user, err := s.userRepo.GetByEmail(req.Email)
+	if err != nil || user == nil {
+		return ErrInvalidUnlockToken
+	}
+	return s.loginGuard.Unlock(user, req.Token, ip)
+}
+
 func (s *UserService) GetUserProfile(userID int64) (*models.User, error) {
 	return s.userRepo.GetByID(userID)
 }
 
-func (s *UserService) UpdateUserProfile(userID int64, updates map[string]interface{}) error {
+var (
+	ErrUsernameTaken       = errors.New("username already exists")
+	ErrInvalidUsername     = errors.New("username may only contain letters, digits, '_' and '-' (3-50 characters)")
+	ErrInvalidAvatarURL    = errors.New("avatar_url must be an http or https URL")
+	ErrInvalidWebsite      = errors.New("website must be an http or https URL")
+	ErrInvalidGitHubHandle = errors.New("github_handle is not a valid GitHub username")
+)
+
+var (
+	usernamePattern     = regexp.MustCompile(`^[A-Za-z0-9_-]{3,50}$`)
+	githubHandlePattern = regexp.MustCompile(`^[A-Za-z0-9](-?[A-Za-z0-9]){0,38}$`)
+)
+
+// UpdateProfileRequest 资料更新。所有字段可选，未传的字段保持不变，传空字符串表示清空
+type UpdateProfileRequest struct {
+	Username     *string   `json:"username" binding:"omitempty,max=50"`
+	AvatarURL    *string   `json:"avatar_url" binding:"omitempty,max=500"`
+	Bio          *string   `json:"bio" binding:"omitempty,max=500"`
+	TechStack    *[]string `json:"tech_stack" binding:"omitempty,max=20,dive,max=30"`
+	Location     *string   `json:"location" binding:"omitempty,max=100"`
+	Website      *string   `json:"website" binding:"omitempty,max=500"`
+	GitHubHandle *string   `json:"github_handle" binding:"omitempty,max=40"`
+	OpenToWork   *bool     `json:"open_to_work"`
+}
+
+// UpdateUserProfile 校验并保存资料，返回更新后的用户。改名后旧用户名跳转到新用户名
+func (s *UserService) UpdateUserProfile(userID int64, req *UpdateProfileRequest) (*models.User, error) {
 	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	oldUsername := user.Username
 
-	// 更新允许的字段
-	if bio, ok := updates["bio"].(string); ok {
-		user.Bio = bio
+	if req.Username != nil && strings.TrimSpace(*req.Username) != user.Username {
+		username := strings.TrimSpace(*req.Username)
+		if err := s.checkUsernameAvailable(username, userID); err != nil {
+			return nil, err
+		}
+		user.Username = username
 	}
-	if avatarURL, ok := updates["avatar_url"].(string); ok {
+	if req.AvatarURL != nil {
+		avatarURL := strings.TrimSpace(*req.AvatarURL)
+		if avatarURL != "" && !isHTTPURL(avatarURL) {
+			return nil, ErrInvalidAvatarURL
+		}
 		user.AvatarURL = avatarURL
 	}
-	if techStack, ok := updates["tech_stack"].([]string); ok {
-		user.TechStack = strings.Join(techStack, ",")
+	if req.Bio != nil {
+		user.Bio = strings.TrimSpace(*req.Bio)
+	}
+	if req.TechStack != nil {
+		user.TechStack = strings.Join(normalizeTechStack(*req.TechStack), ",")
+	}
+	if req.Location != nil {
+		user.Location = strings.TrimSpace(*req.Location)
+	}
+	if req.Website != nil {
+		website := strings.TrimSpace(*req.Website)
+		if website != "" && !isHTTPURL(website) {
+			return nil, ErrInvalidWebsite
+		}
+		user.Website = website
+	}
+	if req.GitHubHandle != nil {
+		handle := strings.TrimPrefix(strings.TrimSpace(*req.GitHubHandle), "@")
+		if handle != "" && !githubHandlePattern.MatchString(handle) {
+			return nil, ErrInvalidGitHubHandle
+		}
+		user.GitHubHandle = handle
+	}
+	if req.OpenToWork != nil {
+		user.OpenToWork = *req.OpenToWork
 	}
 
-	return s.userRepo.Update(user)
+	if err := s.userRepo.UpdateProfile(user, oldUsername); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// checkUsernameAvailable 检查用户名格式，以及是否已被其他用户使用或保留为其他用户的旧用户名
+func (s *UserService) checkUsernameAvailable(username string, userID int64) error {
+	if !usernamePattern.MatchString(username) {
+		return ErrInvalidUsername
+	}
+
+	existing, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != userID {
+		return ErrUsernameTaken
+	}
+
+	redirect, err := s.userRepo.GetUsernameRedirect(username)
+	if err != nil {
+		return err
+	}
+	if redirect != nil && redirect.UserID != userID {
+		return ErrUsernameTaken
+	}
+	return nil
+}
+
+// ResolveUsername 按用户名查找用户ID。renamed 为 true 表示这是改名前的旧用户名，调用方应跳转到当前用户名
+func (s *UserService) ResolveUsername(username string) (user *models.User, renamed bool, err error) {
+	user, err = s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, false, err
+	}
+	if user != nil {
+		return user, false, nil
+	}
+
+	redirect, err := s.userRepo.GetUsernameRedirect(username)
+	if err != nil {
+		return nil, false, err
+	}
+	if redirect == nil {
+		return nil, false, ErrUserNotFound
+	}
+	if user, err = s.userRepo.GetByID(redirect.UserID); err != nil {
+		return nil, false, ErrUserNotFound
+	}
+	return user, true, nil
+}
+
+// isHTTPURL 是否为带主机名的 http(s) 绝对地址
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+type UpdatePrivacyRequest struct {
+	ProfileVisibility string `json:"profile_visibility" binding:"required,oneof=public followers private"`
+	ShowEmail         bool   `json:"show_email"`
+}
+
+// PublicProfile 其他用户看到的个人主页。Restricted 表示受隐私设置限制，只返回基本信息
+type PublicProfile struct {
+	ID             int64                      `json:"id"`
+	Username       string                     `json:"username"`
+	AvatarURL      string                     `json:"avatar_url"`
+	Email          string                     `json:"email,omitempty"`
+	Bio            string                     `json:"bio,omitempty"`
+	TechStack      []string                   `json:"tech_stack"`
+	Location       string                     `json:"location,omitempty"`
+	Website        string                     `json:"website,omitempty"`
+	GitHubHandle   string                     `json:"github_handle,omitempty"`
+	OpenToWork     bool                       `json:"open_to_work"`
+	IsCreator      bool                       `json:"is_creator"`
+	FollowerCount  int                        `json:"follower_count"`
+	FollowingCount int                        `json:"following_count"`
+	Stats          *repositories.CreatorStats `json:"stats,omitempty"`
+	Restricted     bool                       `json:"restricted"`
+	IsSelf         bool                       `json:"is_self"`
+	IsFollowing    bool                       `json:"is_following"` // 查看者是否关注了该用户
+	FollowsYou     bool                       `json:"follows_you"`  // 该用户是否关注了查看者
+	Blocked        bool                       `json:"blocked"`      // 查看者是否拉黑了该用户
+	Muted          bool                       `json:"muted"`        // 查看者是否静音了该用户
+	CreatedAt      time.Time                  `json:"created_at"`
+}
+
+// UserSummary 用户搜索结果、关注和粉丝列表中的一项
+type UserSummary struct {
+	ID            int64    `json:"id"`
+	Username      string   `json:"username"`
+	AvatarURL     string   `json:"avatar_url"`
+	Bio           string   `json:"bio,omitempty"`
+	TechStack     []string `json:"tech_stack"`
+	IsCreator     bool     `json:"is_creator"`
+	FollowerCount int      `json:"follower_count"`
+}
+
+// profileAccess 查看者与主页用户的关系，以及是否受隐私设置限制
+type profileAccess struct {
+	isSelf      bool
+	isFollowing bool
+	followsYou  bool
+	blocked     bool
+	muted       bool
+	restricted  bool
+}
+
+// checkProfileAccess 判断查看者能否看到用户主页的完整内容，viewerID 为0表示未登录。
+// 注销中的账户和拉黑了查看者的用户对其不可见，返回 ErrUserNotFound
+func (s *UserService) checkProfileAccess(viewerID int64, user *models.User) (*profileAccess, error) {
+	if user.DeletionAt != nil {
+		return nil, ErrUserNotFound
+	}
+
+	access := &profileAccess{isSelf: viewerID == user.ID}
+	if viewerID != 0 && !access.isSelf {
+		blocked, blockedBy, muted, err := NewBlockService().Relation(viewerID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if blockedBy {
+			return nil, ErrUserNotFound
+		}
+		access.blocked, access.muted = blocked, muted
+
+		if access.isFollowing, err = s.userRepo.IsFollowing(viewerID, user.ID); err != nil {
+			return nil, err
+		}
+		if access.followsYou, err = s.userRepo.IsFollowing(user.ID, viewerID); err != nil {
+			return nil, err
+		}
+	}
+
+	access.restricted = access.isRestricted(user.ProfileVisibility)
+	return access, nil
+}
+
+// isRestricted 根据关系和主页可见范围判断是否只能看到基本信息：本人不受限制，拉黑对方后同样只能看到基本信息
+func (a *profileAccess) isRestricted(visibility string) bool {
+	switch {
+	case a.isSelf:
+		return false
+	case a.blocked:
+		return true
+	case visibility == ProfileVisibilityPrivate:
+		return true
+	case visibility == ProfileVisibilityFollowers:
+		return !a.isFollowing
+	}
+	return false
+}
+
+// CheckProfileAccess 检查查看者能否查看用户的项目、关注和粉丝列表，规则与个人主页相同：
+// 用户不可见时返回 ErrUserNotFound，受隐私设置限制时返回 ErrProfileRestricted
+func (s *UserService) CheckProfileAccess(viewerID, userID int64) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	access, err := s.checkProfileAccess(viewerID, user)
+	if err != nil {
+		return err
+	}
+	if access.restricted {
+		return ErrProfileRestricted
+	}
+	return nil
+}
+
+// GetPublicProfile 获取用户主页，viewerID 为0表示未登录
+func (s *UserService) GetPublicProfile(viewerID, userID int64) (*PublicProfile, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	access, err := s.checkProfileAccess(viewerID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &PublicProfile{
+		ID:             user.ID,
+		Username:       user.Username,
+		AvatarURL:      user.AvatarURL,
+		TechStack:      []string{},
+		IsCreator:      user.IsCreator,
+		FollowerCount:  user.FollowerCount,
+		FollowingCount: user.FollowingCount,
+		Restricted:     access.restricted,
+		IsSelf:         access.isSelf,
+		IsFollowing:    access.isFollowing,
+		FollowsYou:     access.followsYou,
+		Blocked:        access.blocked,
+		Muted:          access.muted,
+		CreatedAt:      user.CreatedAt,
+	}
+	if profile.Restricted {
+		return profile, nil
+	}
+
+	profile.Bio = user.Bio
+	profile.TechStack = splitList(user.TechStack)
+	profile.Location = user.Location
+	profile.Website = user.Website
+	profile.GitHubHandle = user.GitHubHandle
+	profile.OpenToWork = user.OpenToWork
+	if user.ShowEmail || profile.IsSelf {
+		profile.Email = user.Email
+	}
+
+	// 本人可以看到包含已隐藏项目的统计
+	if profile.Stats, err = s.projectRepo.GetCreatorStats(user.ID, profile.IsSelf); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// SearchUsers 按用户名、简介和技术栈搜索用户，viewerID 拉黑、静音的用户和拉黑了 viewerID 的用户不返回
+func (s *UserService) SearchUsers(viewerID int64, keyword string, limit, offset int) ([]UserSummary, error) {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return []UserSummary{}, nil
+	}
+
+	hiddenIDs, err := NewBlockService().HiddenUserIDs(viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.userRepo.SearchProfiles(keyword, hiddenIDs, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return newUserSummaries(users), nil
+}
+
+// newUserSummaries 生成用户列表项，只有公开主页的用户展示简介和技术栈
+func newUserSummaries(users []models.User) []UserSummary {
+	results := make([]UserSummary, 0, len(users))
+	for _, user := range users {
+		summary := UserSummary{
+			ID:            user.ID,
+			Username:      user.Username,
+			AvatarURL:     user.AvatarURL,
+			TechStack:     []string{},
+			IsCreator:     user.IsCreator,
+			FollowerCount: user.FollowerCount,
+		}
+		if user.ProfileVisibility == ProfileVisibilityPublic {
+			summary.Bio = user.Bio
+			summary.TechStack = splitList(user.TechStack)
+		}
+		results = append(results, summary)
+	}
+	return results
+}
+
+// UpdatePrivacy 更新个人主页的隐私设置
+func (s *UserService) UpdatePrivacy(userID int64, req *UpdatePrivacyRequest) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.ProfileVisibility = req.ProfileVisibility
+	user.ShowEmail = req.ShowEmail
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 func (s *UserService) FollowUser(followerID, followingID int64) error {
 	if followerID == followingID {
 		return errors.New("cannot follow yourself")
 	}
-	return s.userRepo.FollowUser(followerID, followingID)
+	if err := NewBlockService().CheckInteraction(followerID, followingID); err != nil {
+		return err
+	}
+	if err := s.userRepo.FollowUser(followerID, followingID); err != nil {
+		return err
+	}
+	return NewFollowingFeedService().Invalidate(followerID)
 }
 
 func (s *UserService) UnfollowUser(followerID, followingID int64) error {
-	return s.userRepo.UnfollowUser(followerID, followingID)
+	if err := s.userRepo.UnfollowUser(followerID, followingID); err != nil {
+		return err
+	}
+	return NewFollowingFeedService().Invalidate(followerID)
 }
 
-func (s *UserService) GetFollowers(userID int64, limit, offset int) ([]models.User, error) {
-	return s.userRepo.GetFollowers(userID, limit, offset)
+// GetFollowers 获取粉丝列表，查看者需要能看到该用户的完整主页
+func (s *UserService) GetFollowers(viewerID, userID int64, limit, offset int) ([]UserSummary, error) {
+	if err := s.CheckProfileAccess(viewerID, userID); err != nil {
+		return nil, err
+	}
+	users, err := s.userRepo.GetFollowers(userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return newUserSummaries(users), nil
 }
 
-func (s *UserService) GetFollowing(userID int64, limit, offset int) ([]models.User, error) {
-	return s.userRepo.GetFollowing(userID, limit, offset)
+// GetFollowing 获取关注列表，查看者需要能看到该用户的完整主页
+func (s *UserService) GetFollowing(viewerID, userID int64, limit, offset int) ([]UserSummary, error) {
+	if err := s.CheckProfileAccess(viewerID, userID); err != nil {
+		return nil, err
+	}
+	users, err := s.userRepo.GetFollowing(userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return newUserSummaries(users), nil
 }
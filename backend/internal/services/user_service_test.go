@@ -0,0 +1,102 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsHTTPURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want bool
+	}{
+		{"https://example.com", true},
+		{"http://example.com/avatar.png?size=64", true},
+		{"https://localhost:8080", true},
+		{"ftp://example.com", false},
+		{"javascript:alert(1)", false},
+		{"https:///path-only", false},
+		{"example.com", false},
+		{"/relative/path", false},
+		{"", false},
+		{"http://[::1", false},
+	}
+
+	for _, tt := range tests {
+		if got := isHTTPURL(tt.raw); got != tt.want {
+			t.Errorf("isHTTPURL(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestGitHubHandlePattern(t *testing.T) {
+	tests := []struct {
+		handle string
+		want   bool
+	}{
+		{"octocat", true},
+		{"a", true},
+		{"octo-cat", true},
+		{"Octo-Cat-42", true},
+		{strings.Repeat("a", 39), true},
+		{strings.Repeat("a", 40), false},
+		{"-octocat", false},
+		{"octocat-", false},
+		{"octo--cat", false},
+		{"octo_cat", false},
+		{"octo.cat", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := githubHandlePattern.MatchString(tt.handle); got != tt.want {
+			t.Errorf("githubHandlePattern.MatchString(%q) = %v, want %v", tt.handle, got, tt.want)
+		}
+	}
+}
+
+func TestUsernamePattern(t *testing.T) {
+	tests := []struct {
+		username string
+		want     bool
+	}{
+		{"dev", true},
+		{"dev_swipe-42", true},
+		{strings.Repeat("a", 50), true},
+		{"ab", false},
+		{strings.Repeat("a", 51), false},
+		{"dev swipe", false},
+		{"dev.swipe", false},
+		{"开发者", false},
+	}
+
+	for _, tt := range tests {
+		if got := usernamePattern.MatchString(tt.username); got != tt.want {
+			t.Errorf("usernamePattern.MatchString(%q) = %v, want %v", tt.username, got, tt.want)
+		}
+	}
+}
+
+func TestOAuthUsernameBase(t *testing.T) {
+	tests := []struct {
+		login string
+		want  string
+	}{
+		{"octocat", "octocat"},
+		{"octo.cat", "octocat"},
+		{"ab", "devab"},
+		{"", "dev"},
+		{strings.Repeat("a", 45), strings.Repeat("a", 40)},
+	}
+
+	for _, tt := range tests {
+		got := oauthUsernameBase(tt.login)
+		if got != tt.want {
+			t.Errorf("oauthUsernameBase(%q) = %q, want %q", tt.login, got, tt.want)
+		}
+		// 加上随机后缀后仍然是合法的用户名
+		if !usernamePattern.MatchString(got + "-0000") {
+			t.Errorf("oauthUsernameBase(%q) = %q is not a valid username with a suffix", tt.login, got)
+		}
+	}
+}
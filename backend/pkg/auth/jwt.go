@@ -2,6 +2,7 @@ package auth
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"devswipe-backend/internal/config"
@@ -13,31 +14,43 @@ type Claims struct {
 	UserID   int64  `json:"user_id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Role     string `json:"role"`
 	jwt.RegisteredClaims
 }
 
-var jwtSecret []byte
-
+// InitJWT 使用 HS256 时以配置的密钥初始化密钥环；
+// RS256、EdDSA 的密钥对由 SigningKeyService 从数据库加载后调用 SetKeys
 func InitJWT() {
-	jwtSecret = []byte(config.AppConfig.JWT.SecretKey)
+	if config.AppConfig.JWT.Algorithm == AlgHS256 {
+		SetKeys(NewHMACKey(config.AppConfig.JWT.SecretKey), nil)
+	}
 }
 
-func GenerateToken(userID int64, username, email string) (string, error) {
+// GenerateToken 签发JWT，sessionID 写入 jti，服务端据此校验会话是否仍然有效
+func GenerateToken(userID int64, username, email, role, sessionID string) (string, error) {
+	key, err := currentSigningKey()
+	if err != nil {
+		return "", err
+	}
+
 	expirationTime := time.Now().Add(time.Duration(config.AppConfig.JWT.ExpiresIn) * time.Hour)
 
 	claims := &Claims{
 		UserID:   userID,
 		Username: username,
 		Email:    email,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        sessionID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtSecret)
+	token := jwt.NewWithClaims(key.signingMethod(), claims)
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString(key.signingKey())
 	if err != nil {
 		return "", err
 	}
@@ -45,11 +58,20 @@ func GenerateToken(userID int64, username, email string) (string, error) {
 	return tokenString, nil
 }
 
+// ValidateToken 按头部的 kid 在密钥环中查找密钥校验签名，算法必须与该密钥一致
 func ValidateToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
+		kid, _ := token.Header["kid"].(string)
+		key, ok := lookupKey(kid)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing algorithm %s for key %s", token.Method.Alg(), kid)
+		}
+		return key.verificationKey(), nil
 	})
 
 	if err != nil {
@@ -75,5 +97,5 @@ func RefreshToken(tokenString string) (string, error) {
 	}
 
 	// 生成新token
-	return GenerateToken(claims.UserID, claims.Username, claims.Email)
+	return GenerateToken(claims.UserID, claims.Username, claims.Email, claims.Role, claims.ID)
 }
@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrNoSigningKey         = errors.New("no signing key configured")
+	ErrUnknownKey           = errors.New("unknown signing key")
+)
+
+// Key 密钥环中的一个密钥。HS256 使用 Secret，非对称算法使用 Private/Public
+type Key struct {
+	ID        string
+	Algorithm string
+	Secret    []byte
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+}
+
+// signingKeyRing 当前用于签名的密钥，以及所有仍可用于校验的密钥
+type signingKeyRing struct {
+	mu      sync.RWMutex
+	signing *Key
+	keys    map[string]*Key
+}
+
+var keyRing = &signingKeyRing{keys: make(map[string]*Key)}
+
+// SetKeys 替换密钥环。signing 同时也用于校验，verification 为其它仍在有效期内的密钥
+func SetKeys(signing *Key, verification []*Key) {
+	keys := make(map[string]*Key, len(verification)+1)
+	for _, key := range verification {
+		keys[key.ID] = key
+	}
+	if signing != nil {
+		keys[signing.ID] = signing
+	}
+
+	keyRing.mu.Lock()
+	defer keyRing.mu.Unlock()
+	keyRing.signing = signing
+	keyRing.keys = keys
+}
+
+// SigningKeyID 返回当前签名密钥的 kid
+func SigningKeyID() string {
+	keyRing.mu.RLock()
+	defer keyRing.mu.RUnlock()
+	if keyRing.signing == nil {
+		return ""
+	}
+	return keyRing.signing.ID
+}
+
+func currentSigningKey() (*Key, error) {
+	keyRing.mu.RLock()
+	defer keyRing.mu.RUnlock()
+	if keyRing.signing == nil {
+		return nil, ErrNoSigningKey
+	}
+	return keyRing.signing, nil
+}
+
+func lookupKey(kid string) (*Key, bool) {
+	keyRing.mu.RLock()
+	defer keyRing.mu.RUnlock()
+	key, ok := keyRing.keys[kid]
+	return key, ok
+}
+
+// NewHMACKey 由共享密钥创建 HS256 密钥，kid 取密钥哈希的前几位
+func NewHMACKey(secret string) *Key {
+	sum := sha256.Sum256([]byte(secret))
+	return &Key{
+		ID:        "hs-" + hex.EncodeToString(sum[:6]),
+		Algorithm: AlgHS256,
+		Secret:    []byte(secret),
+	}
+}
+
+// GenerateKey 生成新的非对称密钥对，kid 为公钥指纹
+func GenerateKey(algorithm string) (*Key, error) {
+	var private crypto.Signer
+	switch algorithm {
+	case AlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	case AlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+	return newAsymmetricKey(algorithm, private)
+}
+
+// ParsePrivateKeyPEM 从 PKCS#8 PEM 还原密钥，kid 由公钥重新计算
+func ParsePrivateKeyPEM(algorithm, privatePEM string) (*Key, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, errors.New("invalid private key pem")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedAlgorithm, parsed)
+	}
+	switch private.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != AlgRS256 {
+			return nil, fmt.Errorf("%w: rsa key for %s", ErrUnsupportedAlgorithm, algorithm)
+		}
+	case ed25519.PrivateKey:
+		if algorithm != AlgEdDSA {
+			return nil, fmt.Errorf("%w: ed25519 key for %s", ErrUnsupportedAlgorithm, algorithm)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedAlgorithm, parsed)
+	}
+	return newAsymmetricKey(algorithm, private)
+}
+
+// PrivateKeyPEM 以 PKCS#8 PEM 格式导出私钥，用于持久化
+func (k *Key) PrivateKeyPEM() (string, error) {
+	if k.Private == nil {
+		return "", errors.New("key has no private part")
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+func newAsymmetricKey(algorithm string, private crypto.Signer) (*Key, error) {
+	der, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+	return &Key{
+		ID:        base64.RawURLEncoding.EncodeToString(sum[:12]),
+		Algorithm: algorithm,
+		Private:   private,
+		Public:    private.Public(),
+	}, nil
+}
+
+func (k *Key) signingMethod() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+func (k *Key) signingKey() interface{} {
+	if k.Algorithm == AlgHS256 {
+		return k.Secret
+	}
+	return k.Private
+}
+
+func (k *Key) verificationKey() interface{} {
+	if k.Algorithm == AlgHS256 {
+		return k.Secret
+	}
+	return k.Public
+}
+
+// JWK 单个公钥的 JSON Web Key 表示
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 导出所有可用于校验的公钥，对称密钥不会导出
+func JWKS() JWKSet {
+	keyRing.mu.RLock()
+	defer keyRing.mu.RUnlock()
+
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range keyRing.keys {
+		jwk := JWK{Use: "sig", Alg: key.Algorithm, Kid: key.ID}
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
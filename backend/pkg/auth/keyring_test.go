@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"testing"
+
+	"devswipe-backend/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func init() {
+	config.AppConfig = &config.Config{JWT: config.JWTConfig{ExpiresIn: 1}}
+}
+
+func TestSignAndValidateWithEachAlgorithm(t *testing.T) {
+	for _, algorithm := range []string{AlgRS256, AlgEdDSA} {
+		key, err := GenerateKey(algorithm)
+		if err != nil {
+			t.Fatalf("GenerateKey(%s) returned error: %v", algorithm, err)
+		}
+		SetKeys(key, nil)
+
+		token, err := GenerateToken(1, "dev", "dev@example.com", RoleUser, "session")
+		if err != nil {
+			t.Fatalf("%s: GenerateToken returned error: %v", algorithm, err)
+		}
+		claims, err := ValidateToken(token)
+		if err != nil {
+			t.Fatalf("%s: ValidateToken returned error: %v", algorithm, err)
+		}
+		if claims.UserID != 1 || claims.ID != "session" {
+			t.Fatalf("%s: unexpected claims %+v", algorithm, claims)
+		}
+	}
+}
+
+func TestRotatedKeyStillValidates(t *testing.T) {
+	oldKey, _ := GenerateKey(AlgEdDSA)
+	SetKeys(oldKey, nil)
+	token, err := GenerateToken(1, "dev", "dev@example.com", RoleUser, "session")
+	if err != nil {
+		t.Fatalf("GenerateToken returned error: %v", err)
+	}
+
+	newKey, _ := GenerateKey(AlgRS256)
+	SetKeys(newKey, []*Key{oldKey})
+	if _, err := ValidateToken(token); err != nil {
+		t.Fatalf("token signed by a retired key was rejected: %v", err)
+	}
+
+	SetKeys(newKey, nil)
+	if _, err := ValidateToken(token); err == nil {
+		t.Fatal("token signed by an expired key was accepted")
+	}
+}
+
+func TestRejectsAlgorithmMismatch(t *testing.T) {
+	rsaKey, _ := GenerateKey(AlgRS256)
+	hmacKey := NewHMACKey("secret")
+	SetKeys(rsaKey, []*Key{hmacKey})
+
+	// 声称是 HS256 但使用 RSA 密钥的 kid，必须被拒绝
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{UserID: 1})
+	token.Header["kid"] = rsaKey.ID
+	forged, err := token.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("failed to sign forged token: %v", err)
+	}
+	if _, err := ValidateToken(forged); err == nil {
+		t.Fatal("token with mismatched algorithm was accepted")
+	}
+}
+
+func TestPrivateKeyPEMRoundTripAndJWKS(t *testing.T) {
+	key, _ := GenerateKey(AlgRS256)
+	pem, err := key.PrivateKeyPEM()
+	if err != nil {
+		t.Fatalf("PrivateKeyPEM returned error: %v", err)
+	}
+	parsed, err := ParsePrivateKeyPEM(AlgRS256, pem)
+	if err != nil {
+		t.Fatalf("ParsePrivateKeyPEM returned error: %v", err)
+	}
+	if parsed.ID != key.ID {
+		t.Fatalf("kid changed after round trip: %s != %s", parsed.ID, key.ID)
+	}
+	if _, err := ParsePrivateKeyPEM(AlgEdDSA, pem); err == nil {
+		t.Fatal("rsa key was accepted for EdDSA")
+	}
+
+	edKey, _ := GenerateKey(AlgEdDSA)
+	SetKeys(key, []*Key{edKey, NewHMACKey("secret")})
+	set := JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS returned %d keys, want 2 (symmetric key must not be published)", len(set.Keys))
+	}
+	for _, jwk := range set.Keys {
+		if jwk.Kid == key.ID && (jwk.Kty != "RSA" || jwk.N == "" || jwk.E == "") {
+			t.Fatalf("unexpected rsa jwk: %+v", jwk)
+		}
+		if jwk.Kid == edKey.ID && (jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.X == "") {
+			t.Fatalf("unexpected ed25519 jwk: %+v", jwk)
+		}
+	}
+}
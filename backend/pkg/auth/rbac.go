@@ -0,0 +1,66 @@
+package auth
+
+// 用户角色，按权限从低到高排列
+const (
+	RoleUser      = "user"
+	RoleCreator   = "creator"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// 权限
+const (
+	PermModerateProjects = "projects:moderate" // 隐藏、删除任意项目
+	PermModerateComments = "comments:moderate" // 删除任意评论
+	PermManageTags       = "tags:manage"       // 修改标签、维护同义词、合并标签
+	PermManageUsers      = "users:manage"      // 查看用户、解除锁定
+	PermManageRoles      = "roles:manage"      // 修改用户角色
+	PermReadAuditLogs    = "audit:read"
+	PermReadExperiments  = "experiments:read"
+)
+
+var roleRanks = map[string]int{
+	RoleUser:      1,
+	RoleCreator:   2,
+	RoleModerator: 3,
+	RoleAdmin:     4,
+}
+
+// rolePermissions 每个角色额外拥有的权限，高级角色继承低级角色的全部权限。
+// 所有登录用户都可以发布项目，creator 只标记已发布过项目的用户，没有额外权限
+var rolePermissions = map[string][]string{
+	RoleUser:      {},
+	RoleCreator:   {},
+	RoleModerator: {PermModerateProjects, PermModerateComments, PermManageTags},
+	RoleAdmin:     {PermManageUsers, PermManageRoles, PermReadAuditLogs, PermReadExperiments},
+}
+
+// ValidRole 判断是否为已定义的角色
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAtLeast 判断角色是否不低于指定角色，未知角色按普通用户处理
+func RoleAtLeast(role, required string) bool {
+	rank, ok := roleRanks[role]
+	if !ok {
+		rank = roleRanks[RoleUser]
+	}
+	return rank >= roleRanks[required]
+}
+
+// HasPermission 判断角色是否拥有某个权限
+func HasPermission(role, permission string) bool {
+	for candidate, permissions := range rolePermissions {
+		if !RoleAtLeast(role, candidate) {
+			continue
+		}
+		for _, p := range permissions {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
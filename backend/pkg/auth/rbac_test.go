@@ -0,0 +1,50 @@
+package auth
+
+import "testing"
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		role       string
+		permission string
+		want       bool
+	}{
+		{RoleUser, PermModerateProjects, false},
+		{RoleUser, PermManageTags, false},
+		{RoleUser, PermReadExperiments, false},
+		{RoleCreator, PermModerateComments, false},
+		{RoleModerator, PermModerateProjects, true},
+		{RoleModerator, PermManageTags, true},
+		{RoleModerator, PermManageRoles, false},
+		{RoleAdmin, PermModerateComments, true}, // 继承 moderator 的权限
+		{RoleAdmin, PermManageRoles, true},
+		{"", PermModerateProjects, false}, // 未知角色按普通用户处理
+		{"superuser", PermReadAuditLogs, false},
+	}
+
+	for _, tt := range tests {
+		if got := HasPermission(tt.role, tt.permission); got != tt.want {
+			t.Errorf("HasPermission(%q, %q) = %v, want %v", tt.role, tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestRoleAtLeast(t *testing.T) {
+	tests := []struct {
+		role     string
+		required string
+		want     bool
+	}{
+		{RoleUser, RoleModerator, false},
+		{RoleCreator, RoleModerator, false},
+		{RoleModerator, RoleModerator, true},
+		{RoleAdmin, RoleModerator, true},
+		{"unknown", RoleUser, true},
+		{"unknown", RoleCreator, false},
+	}
+
+	for _, tt := range tests {
+		if got := RoleAtLeast(tt.role, tt.required); got != tt.want {
+			t.Errorf("RoleAtLeast(%q, %q) = %v, want %v", tt.role, tt.required, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package auth
+
+import "strings"
+
+// AccessTokenPrefix 个人访问令牌的前缀，用于和JWT区分
+const AccessTokenPrefix = "dsp_"
+
+// 个人访问令牌的权限范围。JWT不受权限范围限制
+const (
+	ScopeProfileRead       = "profile:read"
+	ScopeProjectsWrite     = "projects:write"
+	ScopeInteractionsRead  = "interactions:read"
+	ScopeInteractionsWrite = "interactions:write"
+)
+
+var validScopes = map[string]bool{
+	ScopeProfileRead:       true,
+	ScopeProjectsWrite:     true,
+	ScopeInteractionsRead:  true,
+	ScopeInteractionsWrite: true,
+}
+
+// ValidScope 判断是否为已定义的权限范围
+func ValidScope(scope string) bool {
+	return validScopes[scope]
+}
+
+// IsAccessToken 判断凭证是否为个人访问令牌
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript 滑动窗口日志限流，在Redis中原子执行，多实例共享同一计数。
+// 使用Redis服务器时间，避免各实例时钟不一致。
+// 一次检查多个键（如IP和用户），所有键都有余量时才按 cost 计数，任一键超限时都不计数。
+// 返回 {是否放行, 每个键的剩余次数和距离窗口内最早请求过期的毫秒数...}
+var slidingWindowScript = redis.NewScript(`
+local window = tonumber(ARGV[1])
+local cost = tonumber(ARGV[2])
+local member = ARGV[3]
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local counts = {}
+local allowed = 1
+for i, key in ipairs(KEYS) do
+	redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+	counts[i] = redis.call('ZCARD', key)
+	if counts[i] + cost > tonumber(ARGV[3 + i]) then
+		allowed = 0
+	end
+end
+
+if allowed == 1 then
+	for i, key in ipairs(KEYS) do
+		for j = 1, cost do
+			redis.call('ZADD', key, now, member .. ':' .. now .. ':' .. j)
+		end
+		redis.call('PEXPIRE', key, window)
+		counts[i] = counts[i] + cost
+	end
+end
+
+local result = {allowed}
+for i, key in ipairs(KEYS) do
+	local reset = window
+	local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+	if oldest[2] then
+		reset = tonumber(oldest[2]) + window - now
+	end
+	table.insert(result, tonumber(ARGV[3 + i]) - counts[i])
+	table.insert(result, reset)
+end
+return result
+`)
+
+// RateLimitKey 一个限流维度的键和窗口内的上限
+type RateLimitKey struct {
+	Key   string
+	Limit int
+}
+
+// RateLimitResult 一次限流检查中某个维度的结果
+type RateLimitResult struct {
+	Allowed   bool // 该维度是否还有 cost 次余量
+	Limit     int
+	Remaining int
+	Reset     time.Duration // 距离窗口内最早一次请求过期的时间
+}
+
+// AllowSlidingWindow 检查并记录一次请求，按 cost 次计数。所有维度都有余量时才放行并计数，
+// 任一维度超限时拒绝且各维度都不计数。返回是否放行及每个维度的结果（顺序与 keys 相同）
+func (c *CacheManager) AllowSlidingWindow(ctx context.Context, keys []RateLimitKey, cost int, window time.Duration) (bool, []*RateLimitResult, error) {
+	if len(keys) == 0 {
+		return true, nil, nil
+	}
+	cost = max(cost, 1)
+
+	redisKeys := make([]string, 0, len(keys))
+	args := []interface{}{window.Milliseconds(), cost, fmt.Sprintf("%d", time.Now().UnixNano())}
+	for _, key := range keys {
+		redisKeys = append(redisKeys, key.Key)
+		args = append(args, key.Limit)
+	}
+
+	values, err := slidingWindowScript.Run(ctx, c.client, redisKeys, args...).Int64Slice()
+	if err != nil {
+		return false, nil, err
+	}
+	if len(values) != 1+2*len(keys) {
+		return false, nil, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	results := make([]*RateLimitResult, 0, len(keys))
+	for i, key := range keys {
+		remaining := values[1+2*i]
+		results = append(results, &RateLimitResult{
+			Allowed:   values[0] == 1 || remaining >= int64(cost),
+			Limit:     key.Limit,
+			Remaining: int(max(remaining, 0)),
+			Reset:     time.Duration(values[2+2*i]) * time.Millisecond,
+		})
+	}
+
+	return values[0] == 1, results, nil
+}
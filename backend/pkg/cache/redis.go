@@ -63,6 +63,15 @@ func (c *CacheManager) Set(ctx context.Context, key string, value interface{}, e
 	return c.client.Set(ctx, key, data, expiration).Err()
 }
 
+// SetNX 仅在键不存在时设置缓存，返回是否设置成功
+func (c *CacheManager) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	return c.client.SetNX(ctx, key, data, expiration).Result()
+}
+
 // Get 获取缓存
 func (c *CacheManager) Get(ctx context.Context, key string, dest interface{}) error {
 	data, err := c.client.Get(ctx, key).Result()
@@ -72,17 +81,109 @@ func (c *CacheManager) Get(ctx context.Context, key string, dest interface{}) er
 	return json.Unmarshal([]byte(data), dest)
 }
 
+// GetMulti 批量获取缓存，一次 MGET 返回命中键的原始 JSON，未命中的键不出现在结果中
+func (c *CacheManager) GetMulti(ctx context.Context, keys []string) (map[string]string, error) {
+	found := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return found, nil
+	}
+	values, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		if data, ok := value.(string); ok {
+			found[keys[i]] = data
+		}
+	}
+	return found, nil
+}
+
+// GetAndDelete 获取并删除缓存，用于一次性的数据
+func (c *CacheManager) GetAndDelete(ctx context.Context, key string, dest interface{}) error {
+	data, err := c.client.GetDel(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), dest)
+}
+
 // Delete 删除缓存
 func (c *CacheManager) Delete(ctx context.Context, key string) error {
 	return c.client.Del(ctx, key).Err()
 }
 
+// DeleteKeys 批量删除缓存
+func (c *CacheManager) DeleteKeys(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// DeleteByPattern 删除匹配模式的所有键
+func (c *CacheManager) DeleteByPattern(ctx context.Context, pattern string) error {
+	iter := c.client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		if err := c.client.Del(ctx, iter.Val()).Err(); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
 // Exists 检查键是否存在
 func (c *CacheManager) Exists(ctx context.Context, key string) (bool, error) {
 	result, err := c.client.Exists(ctx, key).Result()
 	return result > 0, err
 }
 
+// Increment 计数器加一，首次创建时设置过期时间
+func (c *CacheManager) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	pipe := c.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, expiration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// TTL 获取键的剩余过期时间，键不存在时返回0
+func (c *CacheManager) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.client.PTTL(ctx, key).Result()
+	if err != nil || ttl < 0 {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// AcquireLock 获取一个带过期时间的简单分布式锁，已被占用时返回false
+func (c *CacheManager) AcquireLock(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, key, time.Now().Unix(), expiration).Result()
+}
+
+// ReplaceSortedSet 原子地替换整个有序集合
+func (c *CacheManager) ReplaceSortedSet(ctx context.Context, key string, members []redis.Z, expiration time.Duration) error {
+	if len(members) == 0 {
+		return c.client.Del(ctx, key).Err()
+	}
+
+	tmpKey := key + ":tmp"
+	pipe := c.client.TxPipeline()
+	pipe.Del(ctx, tmpKey)
+	pipe.ZAdd(ctx, tmpKey, members...)
+	pipe.Expire(ctx, tmpKey, expiration)
+	pipe.Rename(ctx, tmpKey, key)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetSortedSetRevRange 按分数从高到低获取有序集合中的成员
+func (c *CacheManager) GetSortedSetRevRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return c.client.ZRevRange(ctx, key, start, stop).Result()
+}
+
 // 用户相关缓存方法
 func (c *CacheManager) CacheUserFeed(ctx context.Context, userID int64, projects interface{}) error {
 	key := fmt.Sprintf("user_feed:%d", userID)
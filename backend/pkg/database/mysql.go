@@ -58,12 +58,28 @@ func AutoMigrate() error {
 		&models.User{},
 		&models.UserPreferences{},
 		&models.UserFollow{},
+		&models.UserBlock{},
+		&models.UserMute{},
+		&models.UsernameRedirect{},
+		&models.UserToken{},
+		&models.UserIdentity{},
+		&models.PersonalAccessToken{},
+		&models.UserSession{},
+		&models.UserMFA{},
+		&models.MFARecoveryCode{},
 		&models.Project{},
 		&models.ProjectTag{},
+		&models.Tag{},
+		&models.TagSynonym{},
+		&models.ProjectBanditStats{},
+		&models.ProjectUpdate{},
 		&models.UserInteraction{},
 		&models.Comment{},
 		&models.Collection{},
 		&models.CollectionItem{},
+		&models.ExperimentExposure{},
+		&models.AuditLog{},
+		&models.SigningKey{},
 	)
 
 	if err != nil {
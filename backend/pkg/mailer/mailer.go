@@ -0,0 +1,65 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"devswipe-backend/internal/config"
+)
+
+// Message 一封纯文本邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口，生产环境使用SMTP，本地开发可写入日志或文件
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer 根据配置的驱动创建Mailer：smtp、file 或 log（默认）
+func NewMailer() Mailer {
+	cfg := config.AppConfig.Mail
+
+	switch strings.ToLower(cfg.Driver) {
+	case "smtp":
+		return &SMTPMailer{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		}
+	case "file":
+		return &FileMailer{Dir: cfg.FileDir, From: cfg.From}
+	default:
+		return &LogMailer{From: cfg.From}
+	}
+}
+
+// LogMailer 将邮件内容写入服务日志，仅用于本地开发
+type LogMailer struct {
+	From string
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("Mail from %s to %s: %s\n%s", m.From, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// formatMessage 生成RFC 5322格式的邮件内容
+func formatMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+)
+
+// SMTPMailer 通过SMTP服务器发送邮件，配置了用户名时使用PLAIN认证。
+// From 可以带显示名（如 DevSwipe <no-reply@example.com>），信封发件人只使用其中的邮箱地址
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	sender, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address %q: %w", m.From, err)
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	addr := net.JoinHostPort(m.Host, m.Port)
+	return smtp.SendMail(addr, auth, sender.Address, []string{msg.To}, formatMessage(sender.String(), msg))
+}
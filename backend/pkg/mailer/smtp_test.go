@@ -0,0 +1,101 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+)
+
+// serveSMTP 最小的SMTP服务器，只接收一封邮件并返回收到的命令
+func serveSMTP(t *testing.T, listener net.Listener) <-chan []string {
+	t.Helper()
+
+	commands := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			commands <- nil
+			return
+		}
+		defer conn.Close()
+
+		var received []string
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				break
+			}
+			line = strings.TrimRight(line, "\r\n")
+			received = append(received, line)
+
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 localhost")
+			case line == "DATA":
+				reply("354 end with .")
+				for {
+					data, err := reader.ReadString('\n')
+					if err != nil || data == ".\r\n" {
+						break
+					}
+					received = append(received, strings.TrimRight(data, "\r\n"))
+				}
+				reply("250 OK")
+			case line == "QUIT":
+				reply("221 bye")
+				commands <- received
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+		commands <- received
+	}()
+	return commands
+}
+
+func TestSMTPMailerUsesBareEnvelopeSender(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	commands := serveSMTP(t, listener)
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	m := &SMTPMailer{Host: host, Port: port, From: "DevSwipe <no-reply@devswipe.local>"}
+	if err := m.Send(context.Background(), Message{To: "user@example.com", Subject: "hello", Body: "hi"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	received := <-commands
+	var mailFrom, fromHeader string
+	for _, line := range received {
+		switch {
+		case strings.HasPrefix(line, "MAIL FROM:"):
+			mailFrom = line
+		case strings.HasPrefix(line, "From: "):
+			fromHeader = line
+		}
+	}
+
+	if !strings.HasPrefix(mailFrom, "MAIL FROM:<no-reply@devswipe.local>") {
+		t.Errorf("envelope sender = %q, want MAIL FROM:<no-reply@devswipe.local>", mailFrom)
+	}
+	if fromHeader != `From: "DevSwipe" <no-reply@devswipe.local>` {
+		t.Errorf("From header = %q, want display name kept", fromHeader)
+	}
+}
+
+func TestSMTPMailerRejectsInvalidSender(t *testing.T) {
+	m := &SMTPMailer{Host: "127.0.0.1", Port: "1", From: "not an address"}
+	if err := m.Send(context.Background(), Message{To: "user@example.com"}); err == nil {
+		t.Fatal("Send() error = nil, want invalid sender error")
+	}
+}
@@ -0,0 +1,149 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"devswipe-backend/internal/config"
+)
+
+// GitHubProvider GitHub OAuth App 登录。接口地址可配置，便于对接本地的模拟服务器
+type GitHubProvider struct {
+	clientID     string
+	clientSecret string
+	authURL      string
+	tokenURL     string
+	apiURL       string
+	scopes       []string
+}
+
+func NewGitHubProvider(cfg config.OAuthProviderConfig) *GitHubProvider {
+	return &GitHubProvider{
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		authURL:      cfg.AuthURL,
+		tokenURL:     cfg.TokenURL,
+		apiURL:       strings.TrimRight(cfg.APIURL, "/"),
+		scopes:       cfg.Scopes,
+	}
+}
+
+func (p *GitHubProvider) Name() string { return "github" }
+
+func (p *GitHubProvider) AuthCodeURL(state, codeChallenge, redirectURI string) string {
+	params := url.Values{}
+	params.Set("client_id", p.clientID)
+	params.Set("redirect_uri", redirectURI)
+	params.Set("scope", strings.Join(p.scopes, " "))
+	params.Set("state", state)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+	return p.authURL + "?" + params.Encode()
+}
+
+func (p *GitHubProvider) Exchange(ctx context.Context, code, codeVerifier, redirectURI string) (*Token, error) {
+	form := url.Values{}
+	form.Set("client_id", p.clientID)
+	form.Set("client_secret", p.clientSecret)
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var result struct {
+		Token
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := doJSON(req, &result); err != nil {
+		return nil, err
+	}
+
+	// GitHub 在换取失败时同样返回200，错误信息放在响应体中
+	if result.Error != "" {
+		return nil, fmt.Errorf("github token exchange failed: %s: %s", result.Error, result.ErrorDescription)
+	}
+	if result.AccessToken == "" {
+		return nil, fmt.Errorf("github token exchange returned no access token")
+	}
+	return &result.Token, nil
+}
+
+func (p *GitHubProvider) FetchIdentity(ctx context.Context, token *Token) (*Identity, error) {
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := p.get(ctx, token, "/user", &user); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider:       p.Name(),
+		ProviderUserID: strconv.FormatInt(user.ID, 10),
+		Login:          user.Login,
+		Name:           user.Name,
+		AvatarURL:      user.AvatarURL,
+	}
+
+	// 公开资料中的邮箱不一定经过验证，以 /user/emails 中的主邮箱为准
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.get(ctx, token, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+
+	return identity, nil
+}
+
+func (p *GitHubProvider) get(ctx context.Context, token *Token, path string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	return doJSON(req, dest)
+}
+
+// doJSON 发送请求并解析JSON响应，非2xx状态码视为错误
+func doJSON(req *http.Request, dest interface{}) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %d: %s", req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, dest)
+}
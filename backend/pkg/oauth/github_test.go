@@ -0,0 +1,116 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"devswipe-backend/internal/config"
+)
+
+// newStubGitHub 模拟 GitHub 的令牌和用户接口，校验 PKCE 的 code_verifier
+func newStubGitHub(t *testing.T, challenge string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("parse form: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.Form.Get("code") != "good-code" || ChallengeS256(r.Form.Get("code_verifier")) != challenge {
+			json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code", "error_description": "The code passed is incorrect or expired."})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "stub-token", "token_type": "bearer"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer stub-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 42, "login": "octocat", "name": "The Octocat"})
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"email": "secondary@example.com", "primary": false, "verified": true},
+			{"email": "octocat@example.com", "primary": true, "verified": true},
+		})
+	})
+	return httptest.NewServer(mux)
+}
+
+func newStubProvider(server *httptest.Server) *GitHubProvider {
+	return NewGitHubProvider(config.OAuthProviderConfig{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		AuthURL:      server.URL + "/login/oauth/authorize",
+		TokenURL:     server.URL + "/login/oauth/access_token",
+		APIURL:       server.URL,
+		Scopes:       []string{"read:user", "user:email"},
+	})
+}
+
+func TestGitHubExchangeAndFetchIdentity(t *testing.T) {
+	verifier, err := GenerateVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newStubGitHub(t, ChallengeS256(verifier))
+	defer server.Close()
+	provider := newStubProvider(server)
+
+	token, err := provider.Exchange(context.Background(), "good-code", verifier, "http://localhost/callback")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	identity, err := provider.FetchIdentity(context.Background(), token)
+	if err != nil {
+		t.Fatalf("FetchIdentity: %v", err)
+	}
+	if identity.ProviderUserID != "42" || identity.Login != "octocat" {
+		t.Errorf("identity = %+v", identity)
+	}
+	if identity.Email != "octocat@example.com" || !identity.EmailVerified {
+		t.Errorf("expected verified primary email, got %q verified=%v", identity.Email, identity.EmailVerified)
+	}
+}
+
+func TestGitHubExchangeRejectsWrongVerifier(t *testing.T) {
+	verifier, _ := GenerateVerifier()
+	server := newStubGitHub(t, ChallengeS256(verifier))
+	defer server.Close()
+
+	_, err := newStubProvider(server).Exchange(context.Background(), "good-code", "wrong-verifier", "http://localhost/callback")
+	if err == nil {
+		t.Fatal("expected exchange with wrong verifier to fail")
+	}
+}
+
+func TestGitHubAuthCodeURL(t *testing.T) {
+	server := newStubGitHub(t, "")
+	defer server.Close()
+
+	raw := newStubProvider(server).AuthCodeURL("state-1", "challenge-1", "http://localhost/callback")
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	for key, want := range map[string]string{
+		"client_id":             "client",
+		"state":                 "state-1",
+		"code_challenge":        "challenge-1",
+		"code_challenge_method": "S256",
+		"redirect_uri":          "http://localhost/callback",
+		"scope":                 "read:user user:email",
+	} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"devswipe-backend/internal/config"
+)
+
+var ErrUnknownProvider = errors.New("unknown oauth provider")
+
+// Token 授权码换取的访问令牌
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	Scope       string `json:"scope"`
+}
+
+// Identity 第三方平台上的用户身份
+type Identity struct {
+	Provider       string
+	ProviderUserID string
+	Login          string
+	Name           string
+	Email          string
+	EmailVerified  bool
+	AvatarURL      string
+}
+
+// Provider OAuth2 授权码流程（PKCE）的第三方登录提供方，新增 GitLab 等平台时实现该接口
+type Provider interface {
+	Name() string
+	// AuthCodeURL 生成跳转到第三方授权页的地址
+	AuthCodeURL(state, codeChallenge, redirectURI string) string
+	// Exchange 使用授权码和 code_verifier 换取访问令牌
+	Exchange(ctx context.Context, code, codeVerifier, redirectURI string) (*Token, error)
+	// FetchIdentity 获取令牌对应的用户身份
+	FetchIdentity(ctx context.Context, token *Token) (*Identity, error)
+}
+
+// httpClient 调用第三方接口使用的客户端
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Providers 根据配置返回已启用（配置了 Client ID）的提供方
+func Providers() map[string]Provider {
+	providers := make(map[string]Provider)
+	if cfg := config.AppConfig.OAuth.GitHub; cfg.ClientID != "" {
+		providers["github"] = NewGitHubProvider(cfg)
+	}
+	return providers
+}
+
+// GetProvider 获取指定名称的已启用提供方
+func GetProvider(name string) (Provider, error) {
+	provider, ok := Providers()[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
@@ -0,0 +1,31 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// GenerateVerifier 生成 PKCE 的 code_verifier（RFC 7636，43个字符）
+func GenerateVerifier() (string, error) {
+	return randomString(32)
+}
+
+// GenerateState 生成防 CSRF 的 state 参数
+func GenerateState() (string, error) {
+	return randomString(24)
+}
+
+// ChallengeS256 计算 S256 方式的 code_challenge
+func ChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,63 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret RFC 6238 附录B中SHA1测试向量使用的密钥 "12345678901234567890"
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeRFC6238Vectors(t *testing.T) {
+	// RFC 给出的是8位验证码，这里取后6位
+	vectors := map[int64]string{
+		59:         "94287082",
+		1111111109: "07081804",
+		1111111111: "14050471",
+		1234567890: "89005924",
+		2000000000: "69279037",
+	}
+
+	for unix, want := range vectors {
+		got, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatalf("Code(%d) returned error: %v", unix, err)
+		}
+		if got != want[2:] {
+			t.Errorf("Code(%d) = %s, want %s", unix, got, want[2:])
+		}
+	}
+}
+
+func TestValidateAllowsSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	previous, _ := Code(rfcSecret, Step(now)-1)
+
+	step, ok := Validate(rfcSecret, previous, now, 1)
+	if !ok || step != Step(now)-1 {
+		t.Fatalf("Validate with skew 1 = (%d, %v), want (%d, true)", step, ok, Step(now)-1)
+	}
+	if _, ok := Validate(rfcSecret, previous, now, 0); ok {
+		t.Fatal("Validate with skew 0 accepted a code from the previous step")
+	}
+	if _, ok := Validate(rfcSecret, "12345", now, 1); ok {
+		t.Fatal("Validate accepted a code with the wrong length")
+	}
+}
+
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret returned error: %v", err)
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Fatalf("generated secret is not valid base32: %v", err)
+	}
+
+	uri := URI("DevSwipe", "dev@example.com", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/DevSwipe:dev@example.com?") || !strings.Contains(uri, "secret="+secret) {
+		t.Fatalf("unexpected otpauth uri: %s", uri)
+	}
+}
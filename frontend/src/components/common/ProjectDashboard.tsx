@@ -32,13 +32,7 @@ const ProjectDashboard: React.FC<ProjectDashboardProps> = ({ onProjectClick }) =
       user: {
         id: 1,
         username: 'TechExplorer',
-        email: 'tech@example.com',
-        tech_stack: 'React,TypeScript,Python',
         is_creator: true,
-        follower_count: 1234,
-        following_count: 567,
-        created_at: '2023-06-01T00:00:00Z',
-        updated_at: '2024-01-20T00:00:00Z',
       },
       tags: [
         { id: 1, project_id: 1, tag_name: 'AI', tag_type: 'tech', created_at: '2024-01-15T10:30:00Z' },
@@ -68,13 +62,7 @@ const ProjectDashboard: React.FC<ProjectDashboardProps> = ({ onProjectClick }) =
       user: {
         id: 1,
         username: 'TechExplorer',
-        email: 'tech@example.com',
-        tech_stack: 'React,TypeScript,Go',
         is_creator: true,
-        follower_count: 1234,
-        following_count: 567,
-        created_at: '2023-06-01T00:00:00Z',
-        updated_at: '2024-02-10T00:00:00Z',
       },
       tags: [
         { id: 4, project_id: 2, tag_name: 'React', tag_type: 'tech', created_at: '2024-02-01T08:15:00Z' },
@@ -104,13 +92,7 @@ const ProjectDashboard: React.FC<ProjectDashboardProps> = ({ onProjectClick }) =
       user: {
         id: 1,
         username: 'TechExplorer',
-        email: 'tech@example.com',
-        tech_stack: 'Python,TensorFlow,React',
         is_creator: true,
-        follower_count: 1234,
-        following_count: 567,
-        created_at: '2023-06-01T00:00:00Z',
-        updated_at: '2024-02-20T00:00:00Z',
       },
       tags: [
         { id: 7, project_id: 3, tag_name: 'AI', tag_type: 'tech', created_at: '2024-02-15T12:00:00Z' },
@@ -140,13 +122,7 @@ const ProjectDashboard: React.FC<ProjectDashboardProps> = ({ onProjectClick }) =
       user: {
         id: 1,
         username: 'TechExplorer',
-        email: 'tech@example.com',
-        tech_stack: 'Solidity,Web3.js,React',
         is_creator: true,
-        follower_count: 1234,
-        following_count: 567,
-        created_at: '2023-06-01T00:00:00Z',
-        updated_at: '2024-03-05T00:00:00Z',
       },
       tags: [
         { id: 10, project_id: 4, tag_name: 'Blockchain', tag_type: 'tech', created_at: '2024-03-01T15:20:00Z' },
@@ -12,7 +12,7 @@ import {
   updateUser,
   clearError 
 } from '../store/slices/authSlice';
-import { LoginRequest, RegisterRequest, User } from '../types';
+import { LoginRequest, RegisterRequest, UpdateProfileRequest } from '../types';
 import apiService from '../services/api';
 
 export const useAuth = () => {
@@ -49,19 +49,16 @@ export const useAuth = () => {
     dispatch(logout());
   }, [dispatch]);
 
-  const updateUserProfile = useCallback(async (userData: Partial<User>) => {
+  const updateUserProfile = useCallback(async (userData: UpdateProfileRequest) => {
     try {
-      await apiService.updateProfile(userData);
-      if (user) {
-        const updatedUser = { ...user, ...userData };
-        dispatch(updateUser(updatedUser));
-      }
+      const res = await apiService.updateProfile(userData);
+      dispatch(updateUser(res.user));
       return { success: true };
     } catch (error: any) {
       const errorMessage = error.response?.data?.error || '更新失败';
       return { success: false, error: errorMessage };
     }
-  }, [dispatch, user]);
+  }, [dispatch]);
 
   const clearAuthError = useCallback(() => {
     dispatch(clearError());
@@ -44,9 +44,19 @@ const Profile: React.FC = () => {
           if (currentUser) setProfileUser(currentUser as User);
         }
       } else {
-        // 查看他人资料：后端暂无按ID获取用户资料接口，这里先用精简信息展示
-        // 为了至少显示用户名等信息，回退为空并仅展示项目列表
-        setProfileUser(null);
+        // 查看他人资料：受隐私设置限制时只返回用户名、头像和关注数，也看不到项目列表
+        const profile = await apiService.getUser(viewingUserId);
+        setProfileUser({
+          ...profile,
+          email: profile.email || '',
+          tech_stack: profile.tech_stack.join(','),
+          updated_at: profile.created_at,
+        });
+        setIsFollowing(profile.is_following);
+        if (profile.restricted) {
+          setProjects([]);
+          return;
+        }
       }
 
       // 加载该用户的项目
@@ -1,7 +1,9 @@
-import React, { useState, useRef } from 'react';
+import React, { useState, useRef, useEffect } from 'react';
 import { motion } from 'motion/react';
 import { useNavigate } from 'react-router-dom';
 import { X, Upload, Tag, FileText, Image, Video } from 'lucide-react';
+import { apiService } from '../services/api';
+import { TagCount } from '../types';
 
 const Publish: React.FC = () => {
   const navigate = useNavigate();
@@ -11,6 +13,7 @@ const Publish: React.FC = () => {
     tags: [] as string[]
   });
   const [tagInput, setTagInput] = useState('');
+  const [tagSuggestions, setTagSuggestions] = useState<TagCount[]>([]);
   const [uploadedFiles, setUploadedFiles] = useState<File[]>([]);
   const [isLoading, setIsLoading] = useState(false);
   const fileInputRef = useRef<HTMLInputElement>(null);
@@ -23,17 +26,40 @@ const Publish: React.FC = () => {
     }));
   };
 
+  // 输入标签时按前缀获取已有标签作为建议，减少同一技术的不同写法
+  useEffect(() => {
+    const prefix = tagInput.trim();
+    if (!prefix) {
+      setTagSuggestions([]);
+      return;
+    }
+    const timer = setTimeout(async () => {
+      try {
+        const res = await apiService.suggestTags(prefix, 8);
+        setTagSuggestions(res.tags);
+      } catch (error) {
+        setTagSuggestions([]);
+      }
+    }, 200);
+    return () => clearTimeout(timer);
+  }, [tagInput]);
+
+  const addTag = (newTag: string) => {
+    const exists = formData.tags.some(tag => tag.toLowerCase() === newTag.toLowerCase());
+    if (!exists) {
+      setFormData(prev => ({
+        ...prev,
+        tags: [...prev.tags, newTag]
+      }));
+    }
+    setTagInput('');
+    setTagSuggestions([]);
+  };
+
   const handleAddTag = (e: React.KeyboardEvent<HTMLInputElement>) => {
     if (e.key === 'Enter' && tagInput.trim()) {
       e.preventDefault();
-      const newTag = tagInput.trim();
-      if (!formData.tags.includes(newTag)) {
-        setFormData(prev => ({
-          ...prev,
-          tags: [...prev.tags, newTag]
-        }));
-        setTagInput('');
-      }
+      addTag(tagInput.trim());
     }
   };
 
@@ -204,6 +230,21 @@ const Publish: React.FC = () => {
                 placeholder="输入技术栈标签，按回车添加..."
                 className="w-full bg-white/[0.05] border border-white/[0.1] rounded-xl px-4 py-3 text-white placeholder-white/40 focus:outline-none focus:border-blue-400/50 focus:bg-white/[0.08] transition-all duration-200 mb-3"
               />
+              {tagSuggestions.length > 0 && (
+                <div className="flex flex-wrap gap-2 mb-3">
+                  {tagSuggestions.map(suggestion => (
+                    <button
+                      key={suggestion.id}
+                      type="button"
+                      onClick={() => addTag(suggestion.name)}
+                      className="bg-white/[0.05] text-white/70 px-3 py-1 rounded-lg text-sm border border-white/[0.1] hover:bg-white/[0.1] hover:text-white transition-colors"
+                    >
+                      {suggestion.name}
+                      <span className="ml-1 text-white/40">{suggestion.project_count}</span>
+                    </button>
+                  ))}
+                </div>
+              )}
               {formData.tags.length > 0 && (
                 <div className="flex flex-wrap gap-2">
                   {formData.tags.map((tag, index) => (
@@ -2,7 +2,16 @@ import React, { useState, useEffect } from 'react';
 import { useSearchParams } from 'react-router-dom';
 import ProjectCard from '../components/project/ProjectCard';
 import { apiService } from '../services/api';
-import { Project } from '../types';
+import { Project, TagCount, TagPage, TagType } from '../types';
+
+const TAG_TYPES: { value: TagType | ''; label: string }[] = [
+  { value: '', label: '全部' },
+  { value: 'tech', label: '技术' },
+  { value: 'domain', label: '领域' },
+  { value: 'function', label: '功能' },
+  { value: 'stage', label: '阶段' },
+  { value: 'hackathon', label: '黑客松' },
+];
 
 const Search: React.FC = () => {
   const [searchParams, setSearchParams] = useSearchParams();
@@ -10,6 +19,9 @@ const Search: React.FC = () => {
   const [projects, setProjects] = useState<Project[]>([]);
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
+  const [tagType, setTagType] = useState<TagType | ''>('');
+  const [popularTags, setPopularTags] = useState<TagCount[]>([]);
+  const [tagPage, setTagPage] = useState<TagPage | null>(null);
 
   const handleSearch = async (searchKeyword: string) => {
     if (!searchKeyword.trim()) {
@@ -37,15 +49,46 @@ const Search: React.FC = () => {
     handleSearch(keyword);
   };
 
-  // 页面加载时如果有搜索参数，自动搜索
+  const loadTag = async (name: string) => {
+    setLoading(true);
+    setError(null);
+    try {
+      setTagPage(await apiService.getTag(name));
+    } catch (err: any) {
+      setTagPage(null);
+      setError(err.response?.data?.error || '标签加载失败');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const openTag = (name: string) => {
+    setKeyword('');
+    setProjects([]);
+    setSearchParams({ tag: name });
+  };
+
+  // 页面加载时如果有搜索参数，自动搜索；带 tag 参数时展示标签页
   useEffect(() => {
     const query = searchParams.get('q');
-    if (query) {
-      setKeyword(query);
-      handleSearch(query);
+    const tag = searchParams.get('tag');
+    if (tag) {
+      loadTag(tag);
+    } else {
+      setTagPage(null);
+      if (query) {
+        setKeyword(query);
+        handleSearch(query);
+      }
     }
   }, [searchParams]);
 
+  useEffect(() => {
+    apiService.getPopularTags(tagType || undefined, 30)
+      .then(res => setPopularTags(res.tags))
+      .catch(() => setPopularTags([]));
+  }, [tagType]);
+
   return (
     <div className="min-h-screen bg-gray-50 py-8">
         <div className="max-w-4xl mx-auto px-4">
@@ -71,6 +114,82 @@ const Search: React.FC = () => {
             </form>
           </div>
 
+          {/* 热门标签 */}
+          {!tagPage && (
+            <div className="mb-8">
+              <div className="flex flex-wrap gap-2 mb-3">
+                {TAG_TYPES.map(type => (
+                  <button
+                    key={type.value}
+                    onClick={() => setTagType(type.value)}
+                    className={`px-3 py-1 rounded-full text-sm ${
+                      tagType === type.value ? 'bg-blue-600 text-white' : 'bg-white text-gray-700 border border-gray-300 hover:bg-gray-100'
+                    }`}
+                  >
+                    {type.label}
+                  </button>
+                ))}
+              </div>
+              <div className="flex flex-wrap gap-2">
+                {popularTags.map(tag => (
+                  <button
+                    key={tag.id}
+                    onClick={() => openTag(tag.name)}
+                    className="px-3 py-1 bg-blue-50 text-blue-700 rounded-lg text-sm hover:bg-blue-100"
+                  >
+                    {tag.name}
+                    <span className="ml-1 text-blue-400">{tag.project_count}</span>
+                  </button>
+                ))}
+              </div>
+            </div>
+          )}
+
+          {/* 标签页 */}
+          {tagPage && !loading && (
+            <div>
+              <div className="mb-6">
+                <button onClick={() => setSearchParams({})} className="text-sm text-blue-600 hover:underline mb-2">
+                  ← 返回
+                </button>
+                <h2 className="text-2xl font-semibold text-gray-900">#{tagPage.tag.name}</h2>
+                {tagPage.tag.description && <p className="mt-2 text-gray-600">{tagPage.tag.description}</p>}
+                <p className="mt-1 text-sm text-gray-500">{tagPage.project_count} 个项目</p>
+              </div>
+
+              {tagPage.related.length > 0 && (
+                <div className="mb-6">
+                  <h3 className="text-sm font-medium text-gray-700 mb-2">相关标签</h3>
+                  <div className="flex flex-wrap gap-2">
+                    {tagPage.related.map(tag => (
+                      <button
+                        key={tag.id}
+                        onClick={() => openTag(tag.name)}
+                        className="px-3 py-1 bg-gray-100 text-gray-700 rounded-lg text-sm hover:bg-gray-200"
+                      >
+                        {tag.name}
+                      </button>
+                    ))}
+                  </div>
+                </div>
+              )}
+
+              <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-6">
+                {tagPage.top_projects.map((project, index) => (
+                  <ProjectCard
+                    key={project.id}
+                    project={project}
+                    onSwipe={() => {}}
+                    onBookmark={() => {}}
+                    onComment={() => {}}
+                    currentIndex={index}
+                    totalCount={tagPage.top_projects.length}
+                  />
+                ))}
+              </div>
+            </div>
+          )}
+
           {/* 搜索结果 */}
           <div>
             {error && (
@@ -105,7 +224,7 @@ const Search: React.FC = () => {
               </div>
             )}
 
-            {!loading && !error && !keyword && (
+            {!loading && !error && !keyword && !tagPage && (
               <div className="text-center py-12">
                 <div className="text-gray-400 text-6xl mb-4">💡</div>
                 <h3 className="text-lg font-medium text-gray-900 mb-2">开始搜索项目</h3>
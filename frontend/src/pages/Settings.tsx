@@ -5,9 +5,14 @@ import { useAuth } from '../hooks/useAuth';
 const Settings: React.FC = () => {
   const navigate = useNavigate();
   const { user, updateProfile, isAuthenticated } = useAuth();
+  const [username, setUsername] = useState('');
   const [avatarUrl, setAvatarUrl] = useState('');
   const [bio, setBio] = useState('');
   const [techStackInput, setTechStackInput] = useState('');
+  const [location, setLocation] = useState('');
+  const [website, setWebsite] = useState('');
+  const [githubHandle, setGithubHandle] = useState('');
+  const [openToWork, setOpenToWork] = useState(false);
   const [saving, setSaving] = useState(false);
   const [message, setMessage] = useState('');
   const [error, setError] = useState('');
@@ -18,9 +23,14 @@ const Settings: React.FC = () => {
       return;
     }
     if (user) {
+      setUsername(user.username);
       setAvatarUrl(user.avatar_url || '');
       setBio(user.bio || '');
       setTechStackInput(user.tech_stack || '');
+      setLocation(user.location || '');
+      setWebsite(user.website || '');
+      setGithubHandle(user.github_handle || '');
+      setOpenToWork(!!user.open_to_work);
     }
   }, [isAuthenticated, user, navigate]);
 
@@ -34,10 +44,15 @@ const Settings: React.FC = () => {
       .map(s => s.trim())
       .filter(Boolean);
     const res = await updateProfile({
+      username,
       avatar_url: avatarUrl,
       bio,
-      tech_stack: techStack as any,
-    } as any);
+      tech_stack: techStack,
+      location,
+      website,
+      github_handle: githubHandle,
+      open_to_work: openToWork,
+    });
     if (res.success) {
       setMessage('已保存');
     } else {
@@ -53,6 +68,17 @@ const Settings: React.FC = () => {
         {message && <div className="p-3 bg-green-50 text-green-700 rounded">{message}</div>}
         {error && <div className="p-3 bg-red-50 text-red-700 rounded">{error}</div>}
 
+        <div>
+          <label className="block text-sm font-medium text-gray-700 mb-2">用户名</label>
+          <input
+            type="text"
+            value={username}
+            onChange={(e) => setUsername(e.target.value)}
+            className="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500"
+          />
+          <p className="mt-1 text-xs text-gray-500">改名后，旧用户名的主页链接会跳转到新用户名</p>
+        </div>
+
         <div>
           <label className="block text-sm font-medium text-gray-700 mb-2">头像链接</label>
           <input
@@ -86,6 +112,46 @@ const Settings: React.FC = () => {
           />
         </div>
 
+        <div>
+          <label className="block text-sm font-medium text-gray-700 mb-2">所在地</label>
+          <input
+            type="text"
+            value={location}
+            onChange={(e) => setLocation(e.target.value)}
+            className="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500"
+          />
+        </div>
+
+        <div>
+          <label className="block text-sm font-medium text-gray-700 mb-2">个人网站</label>
+          <input
+            type="url"
+            value={website}
+            onChange={(e) => setWebsite(e.target.value)}
+            className="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500"
+            placeholder="https://example.com"
+          />
+        </div>
+
+        <div>
+          <label className="block text-sm font-medium text-gray-700 mb-2">GitHub 用户名</label>
+          <input
+            type="text"
+            value={githubHandle}
+            onChange={(e) => setGithubHandle(e.target.value)}
+            className="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500"
+          />
+        </div>
+
+        <label className="flex items-center gap-2 text-sm text-gray-700">
+          <input
+            type="checkbox"
+            checked={openToWork}
+            onChange={(e) => setOpenToWork(e.target.checked)}
+          />
+          正在寻找工作机会
+        </label>
+
         <div className="flex gap-3">
           <button
             type="submit"
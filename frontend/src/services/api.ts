@@ -4,6 +4,7 @@ import {
   LoginRequest, 
   RegisterRequest, 
   User, 
+  UpdateProfileRequest,
   Project, 
   CreateProjectRequest, 
   UpdateProjectRequest,
@@ -11,7 +12,12 @@ import {
   CommentRequest,
   Comment,
   ProjectStats,
-  FeedResponse
+  FeedResponse,
+  PublicProfile,
+  UserSummary,
+  TagCount,
+  TagPage,
+  TagType
 } from '../types';
 
 class ApiService {
@@ -81,12 +87,24 @@ class ApiService {
     return response.data;
   }
 
-  async updateProfile(data: Partial<User>): Promise<{ message: string }> {
-    const response: AxiosResponse<{ message: string }> = await this.api.put('/users/me', data);
+  async updateProfile(data: UpdateProfileRequest): Promise<{ message: string; user: User }> {
+    const response: AxiosResponse<{ message: string; user: User }> = await this.api.put('/users/me', data);
     return response.data;
   }
 
   // 用户相关API
+  async getUser(userId: number): Promise<PublicProfile> {
+    const response: AxiosResponse<PublicProfile> = await this.api.get(`/users/${userId}`);
+    return response.data;
+  }
+
+  async searchUsers(q: string, limit = 20, offset = 0): Promise<{ users: UserSummary[] }> {
+    const response: AxiosResponse<{ users: UserSummary[] }> = await this.api.get('/users/search', {
+      params: { q, limit, offset },
+    });
+    return response.data;
+  }
+
   async followUser(userId: number): Promise<{ message: string }> {
     const response: AxiosResponse<{ message: string }> = await this.api.post(`/users/${userId}/follow`);
     return response.data;
@@ -97,15 +115,15 @@ class ApiService {
     return response.data;
   }
 
-  async getFollowers(userId: number, limit = 20, offset = 0): Promise<{ followers: User[] }> {
-    const response: AxiosResponse<{ followers: User[] }> = await this.api.get(
+  async getFollowers(userId: number, limit = 20, offset = 0): Promise<{ followers: UserSummary[] }> {
+    const response: AxiosResponse<{ followers: UserSummary[] }> = await this.api.get(
       `/users/${userId}/followers?limit=${limit}&offset=${offset}`
     );
     return response.data;
   }
 
-  async getFollowing(userId: number, limit = 20, offset = 0): Promise<{ following: User[] }> {
-    const response: AxiosResponse<{ following: User[] }> = await this.api.get(
+  async getFollowing(userId: number, limit = 20, offset = 0): Promise<{ following: UserSummary[] }> {
+    const response: AxiosResponse<{ following: UserSummary[] }> = await this.api.get(
       `/users/${userId}/following?limit=${limit}&offset=${offset}`
     );
     return response.data;
@@ -158,6 +176,28 @@ class ApiService {
     return data;
   }
 
+  // 标签相关API
+  async suggestTags(prefix: string, limit = 10): Promise<{ tags: TagCount[] }> {
+    const response: AxiosResponse<{ tags: TagCount[] }> = await this.api.get('/tags', {
+      params: { prefix, limit },
+    });
+    return response.data;
+  }
+
+  async getPopularTags(type?: TagType, limit = 30): Promise<{ tags: TagCount[] }> {
+    const response: AxiosResponse<{ tags: TagCount[] }> = await this.api.get('/tags/popular', {
+      params: { type, limit },
+    });
+    return response.data;
+  }
+
+  async getTag(name: string): Promise<TagPage> {
+    const response: AxiosResponse<TagPage> = await this.api.get(`/tags/${encodeURIComponent(name)}`);
+    const data = response.data;
+    data.top_projects = data.top_projects.map(p => this.normalizeProject(p));
+    return data;
+  }
+
   async searchProjects(keyword: string, limit = 20, offset = 0): Promise<{ projects: Project[]; keyword: string }> {
     const response: AxiosResponse<{ projects: Project[]; keyword: string }> = await this.api.get(
       `/projects/search?q=${encodeURIComponent(keyword)}&limit=${limit}&offset=${offset}`
@@ -6,6 +6,10 @@ export interface User {
   avatar_url?: string;
   bio?: string;
   tech_stack: string; // 后端返回逗号分隔的字符串
+  location?: string;
+  website?: string;
+  github_handle?: string;
+  open_to_work?: boolean;
   is_creator: boolean;
   follower_count: number;
   following_count: number;
@@ -13,6 +17,68 @@ export interface User {
   updated_at: string;
 }
 
+// 资料更新，未传的字段保持不变
+export interface UpdateProfileRequest {
+  username?: string;
+  avatar_url?: string;
+  bio?: string;
+  tech_stack?: string[];
+  location?: string;
+  website?: string;
+  github_handle?: string;
+  open_to_work?: boolean;
+}
+
+export interface CreatorStats {
+  project_count: number;
+  total_views: number;
+  total_likes: number;
+  total_super_likes: number;
+  total_comments: number;
+}
+
+// 其他用户的公开主页，restricted 为 true 时受隐私设置限制，只有基本信息
+export interface PublicProfile {
+  id: number;
+  username: string;
+  avatar_url: string;
+  email?: string;
+  bio?: string;
+  tech_stack: string[];
+  location?: string;
+  website?: string;
+  github_handle?: string;
+  open_to_work: boolean;
+  is_creator: boolean;
+  follower_count: number;
+  following_count: number;
+  stats?: CreatorStats;
+  restricted: boolean;
+  is_self: boolean;
+  is_following: boolean;
+  follows_you: boolean;
+  created_at: string;
+}
+
+// 项目、评论等内容中的作者信息，只包含公开字段
+export interface Author {
+  id: number;
+  username: string;
+  avatar_url?: string;
+  is_creator: boolean;
+}
+
+// 用户搜索结果、关注和粉丝列表中的一项
+export interface UserSummary {
+  id: number;
+  username: string;
+  avatar_url: string;
+  bio?: string;
+  tech_stack: string[];
+  is_creator: boolean;
+  follower_count: number;
+}
+
 export interface AuthResponse {
   user_id: number;
   username: string;
@@ -52,15 +118,43 @@ export interface Project {
   is_public: boolean;
   created_at: string;
   updated_at: string;
-  user: User;
+  user: Author;
   tags: ProjectTag[];
 }
 
+export type TagType = 'tech' | 'domain' | 'function' | 'stage' | 'hackathon';
+
+// 标签及其公开项目数；相关标签中为同时带有两个标签的项目数
+export interface TagCount {
+  id: number;
+  name: string;
+  slug: string;
+  tag_type: TagType;
+  project_count: number;
+}
+
+export interface Tag {
+  id: number;
+  name: string;
+  slug: string;
+  tag_type: TagType;
+  description: string;
+  synonyms?: { id: number; tag_id: number; synonym: string }[];
+}
+
+export interface TagPage {
+  tag: Tag;
+  project_count: number;
+  related: TagCount[];
+  top_projects: Project[];
+}
+
 export interface ProjectTag {
   id: number;
   project_id: number;
+  tag_id: number;
   tag_name: string;
-  tag_type: 'tech' | 'domain' | 'function' | 'stage' | 'hackathon';
+  tag_type: TagType;
   created_at: string;
 }
 
@@ -116,7 +210,7 @@ export interface UserInteraction {
   session_id: string;
   view_duration: number;
   created_at: string;
-  user: User;
+  user: Author;
   project: Project;
 }
 
@@ -130,7 +224,7 @@ export interface Comment {
   like_count: number;
   created_at: string;
   updated_at: string;
-  user: User;
+  user: Author;
   project: Project;
   parent?: Comment;
   replies: Comment[];
@@ -152,7 +246,7 @@ export interface Collection {
   item_count: number;
   created_at: string;
   updated_at: string;
-  user: User;
+  user: Author;
   items: CollectionItem[];
 }
 